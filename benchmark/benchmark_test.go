@@ -10,7 +10,7 @@ import (
 func BenchmarkS3goCLI(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		cmd := exec.Command("./s3go", "-b", os.Getenv("AWS_S3_BUCKET_NAME"), "-f", "../testdata/earth.jpg")
+		cmd := exec.Command("./s3go", "cp", "../testdata/earth.jpg", fmt.Sprintf("s3://%s/", os.Getenv("AWS_S3_BUCKET_NAME")))
 		err := cmd.Run()
 		if err != nil {
 			b.Fatal(err)
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hikaru7719/s3go/uploader"
+	"github.com/urfave/cli"
+)
+
+// copyObject copies srcKey in srcBucket to dstKey in dstBucket on the server side.
+// When move is true, the source is deleted after it is copied.
+func copyObject(c *cli.Context, srcBucket, srcKey, dstBucket, dstKey string, move bool) error {
+	dstKey, err := copyKey(dstKey, srcKey)
+	if err != nil {
+		return err
+	}
+	if move && srcBucket == dstBucket && srcKey == dstKey {
+		return fmt.Errorf("can't move s3://%s/%s to itself", srcBucket, srcKey)
+	}
+	s3, err := newClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+	info, err := s3.HeadObject(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+
+	sign, err := newSignature(c)
+	if err != nil {
+		return err
+	}
+	opts, err := uploadOptions(c)
+	if err != nil {
+		return err
+	}
+	source := uploader.CopySource{
+		Bucket:      srcBucket,
+		Key:         srcKey,
+		Size:        info.Size,
+		ETag:        info.ETag,
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+	}
+	start := time.Now()
+	if err := uploader.NewCopy(dstBucket, dstKey, source, sign, opts...).RunContext(ctx); err != nil {
+		return err
+	}
+	if !c.Bool("quiet") {
+		fmt.Fprintf(os.Stdout, "copy: s3://%s/%s to s3://%s/%s (%s, %s)\n", srcBucket, srcKey, dstBucket, dstKey, formatSize(info.Size), time.Since(start).Round(time.Millisecond))
+	}
+	if !move {
+		return nil
+	}
+	return s3.DeleteObject(ctx, srcBucket, srcKey)
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hikaru7719/s3go/uploader"
+	"github.com/urfave/cli"
+)
+
+func cpCommand() cli.Command {
+	flags := append(transferFlags(), cli.BoolFlag{
+		Name:  "recursive, r",
+		Usage: "Upload all files under the SOURCE directory with the key as prefix",
+	})
+	return cli.Command{
+		Name:      "cp",
+		Usage:     "Copy a file or directory to S3, an object from S3 to a file, or an object between S3 locations",
+		ArgsUsage: "SOURCE DESTINATION",
+		Flags:     append(flags, dirFlags()...),
+		Action: func(c *cli.Context) error {
+			return transfer(c, false)
+		},
+	}
+}
+
+func mvCommand() cli.Command {
+	flags := append(transferFlags(), cli.BoolFlag{
+		Name:  "recursive, r",
+		Usage: "Move all files under the SOURCE directory with the key as prefix",
+	})
+	return cli.Command{
+		Name:      "mv",
+		Usage:     "Move a file or directory to S3, an object from S3 to a file, or an object between S3 locations",
+		ArgsUsage: "SOURCE DESTINATION",
+		Flags:     append(flags, dirFlags()...),
+		Action: func(c *cli.Context) error {
+			return transfer(c, true)
+		},
+	}
+}
+
+// transfer uploads, downloads or copies SOURCE to DESTINATION.
+// When move is true, the source is deleted after it is transferred.
+func transfer(c *cli.Context, move bool) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("%s requires SOURCE and DESTINATION", c.Command.Name)
+	}
+	src, dst := c.Args().Get(0), c.Args().Get(1)
+	if isS3URI(src) {
+		bucket, key, err := parseS3URI(src)
+		if err != nil {
+			return err
+		}
+		if isS3URI(dst) {
+			if c.Bool("recursive") {
+				return errors.New("--recursive is not supported for copy between S3 objects")
+			}
+			dstBucket, dstKey, err := parseS3URI(dst)
+			if err != nil {
+				return err
+			}
+			return copyObject(c, bucket, key, dstBucket, dstKey, move)
+		}
+		if err := downloadFile(c, bucket, key, dst); err != nil || !move {
+			return err
+		}
+		s3, err := newClient(c)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		return s3.DeleteObject(ctx, bucket, key)
+	}
+
+	bucket, key, err := parseS3URI(dst)
+	if err != nil {
+		return err
+	}
+	if c.Bool("recursive") {
+		return uploadDir(c, src, bucket, key, move)
+	}
+	if move && src == "-" {
+		return errors.New("stdin can't be moved")
+	}
+	key, err = objectKey(key, src)
+	if err != nil {
+		return err
+	}
+	if err := uploadFile(c, src, bucket, key); err != nil || !move {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// dirFlags returns flags to select and upload files in a directory.
+func dirFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "Upload only files matching `Pattern`, which is matched against the relative path or the file name",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Don't upload files matching `Pattern`, which is matched against the relative path or the file name",
+		},
+		cli.StringFlag{
+			Name:  "symlinks",
+			Value: symlinkFollow,
+			Usage: "`Policy` for symbolic links, follow or skip",
+		},
+		cli.IntFlag{
+			Name:  "max-files",
+			Value: 4,
+			Usage: "`Number` of files uploaded at the same time",
+		},
+	}
+}
+
+// keyPrefix returns prefix ending with "/" to which relative paths of files are appended.
+func keyPrefix(prefix string) string {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// walkDir returns files under dir selected by --include, --exclude and --symlinks flags.
+func walkDir(c *cli.Context, dir string) ([]localFile, *fileFilter, error) {
+	filter, err := newFileFilter(c.StringSlice("include"), c.StringSlice("exclude"))
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := walkFiles(dir, c.String("symlinks"), filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, filter, nil
+}
+
+// uploadDir uploads files under dir to bucket with the key prefix.
+// When move is true, the files uploaded successfully are removed.
+func uploadDir(c *cli.Context, dir, bucket, prefix string, move bool) error {
+	files, _, err := walkDir(c, dir)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+	results, err := uploadFiles(ctx, c, dir, bucket, keyPrefix(prefix), files)
+	if err != nil {
+		return err
+	}
+	if move {
+		for i := range results {
+			if results[i].done {
+				results[i].err = os.Remove(results[i].file)
+				results[i].done = results[i].err == nil
+			}
+		}
+	}
+	return printSummary(os.Stdout, results)
+}
+
+// fileResult is the result of uploading a file in a directory or deleting an object.
+type fileResult struct {
+	// action is upload or delete.
+	action  string
+	file    string
+	uri     string
+	size    int64
+	elapsed time.Duration
+	err     error
+	done    bool
+}
+
+// uploadFiles uploads files under dir to bucket with the key prefix.
+// At most max-files files are uploaded at the same time, and the parts of all files share concurrency.
+// Files not started before ctx is canceled are failed with the error of ctx.
+func uploadFiles(ctx context.Context, c *cli.Context, dir, bucket, prefix string, files []localFile) ([]fileResult, error) {
+	if c.String("checkpoint") != "" {
+		return nil, errors.New("--checkpoint can't be used to upload a directory")
+	}
+	opts, err := uploadOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, uploader.WithPartLimiter(uploader.NewPartLimiter(c.Int("concurrency"))))
+	// Each file appends its own key, so opts must not be shared by append.
+	opts = opts[:len(opts):len(opts)]
+	sign, err := newSignature(c)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]fileResult, len(files))
+	for i, f := range files {
+		results[i] = fileResult{
+			action: "upload",
+			file:   filepath.Join(dir, filepath.FromSlash(f.rel)),
+			uri:    "s3://" + bucket + "/" + prefix + f.rel,
+			size:   f.size,
+		}
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	maxFiles := c.Int("max-files")
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	for i := 0; i < maxFiles; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				start := time.Now()
+				upload, err := uploader.New(bucket, files[i].path, sign, append(opts, uploader.WithKey(prefix+files[i].rel))...)
+				if err == nil {
+					err = upload.RunContext(ctx)
+				}
+				results[i].elapsed = time.Since(start)
+				results[i].err = err
+				results[i].done = err == nil
+			}
+		}()
+	}
+	started := 0
+feed:
+	for i := range files {
+		select {
+		case queue <- i:
+			started++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+	for i := started; i < len(results); i++ {
+		results[i].err = ctx.Err()
+	}
+	return results, nil
+}
+
+// printSummary prints the result of each file and returns error when any file failed.
+func printSummary(w io.Writer, results []fileResult) error {
+	var uploaded, deleted, failed int
+	var bytes int64
+	for _, r := range results {
+		target := r.uri
+		if r.action == "upload" {
+			target = r.file + " to " + r.uri
+		}
+		if !r.done {
+			failed++
+			fmt.Fprintf(w, "failed to %s: %s: %v\n", r.action, target, r.err)
+			continue
+		}
+		if r.action == "delete" {
+			deleted++
+			fmt.Fprintf(w, "delete: %s\n", target)
+			continue
+		}
+		uploaded++
+		bytes += r.size
+		fmt.Fprintf(w, "upload: %s (%s, %s)\n", target, formatSize(r.size), r.elapsed.Round(time.Millisecond))
+	}
+	summary := fmt.Sprintf("%d files uploaded (%s)", uploaded, formatSize(bytes))
+	if deleted > 0 {
+		summary += fmt.Sprintf(", %d deleted", deleted)
+	}
+	fmt.Fprintf(w, "%s, %d failed\n", summary, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(results))
+	}
+	return nil
+}
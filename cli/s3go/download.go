@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hikaru7719/s3go/downloader"
+	"github.com/urfave/cli"
+)
+
+// downloadFile downloads key in bucket to file.
+func downloadFile(c *cli.Context, bucket, key, file string) error {
+	file, err := localPath(file, key)
+	if err != nil {
+		return err
+	}
+	endpoint, err := newEndpoint(c)
+	if err != nil {
+		return err
+	}
+	opts := []downloader.Option{
+		downloader.WithEndpoint(endpoint),
+		downloader.WithConcurrency(c.Int("concurrency")),
+		downloader.WithRetryPolicy(retryPolicy(c)),
+	}
+	if p := c.String("part-size"); p != "auto" {
+		partSize, err := parseSize(p)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, downloader.WithPartSize(partSize))
+	}
+	sign, err := newSignature(c)
+	if err != nil {
+		return err
+	}
+	download := downloader.New(bucket, key, file, sign, opts...)
+
+	ctx, cancel := signalContext()
+	defer cancel()
+	start := time.Now()
+	if err := download.RunContext(ctx); err != nil {
+		return err
+	}
+	if !c.Bool("quiet") {
+		fmt.Fprintf(os.Stdout, "download: s3://%s/%s to %s (%s, %s)\n", bucket, key, file, formatSize(download.Size()), time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/urfave/cli"
+)
+
+func lsCommand() cli.Command {
+	return cli.Command{
+		Name:      "ls",
+		Usage:     "List objects and common prefixes under a prefix",
+		ArgsUsage: "s3://bucket/prefix",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "recursive, r",
+				Usage: "List all objects under the prefix instead of grouping them by /",
+			},
+			cli.BoolFlag{
+				Name:  "human-readable, H",
+				Usage: "Show sizes like 1.5MiB instead of bytes",
+			},
+			cli.BoolFlag{
+				Name:  "summarize",
+				Usage: "Show the total number and size of the listed objects",
+			},
+			cli.StringFlag{
+				Name:  "start-after",
+				Usage: "List keys after `Key` in lexical order",
+			},
+			cli.IntFlag{
+				Name:  "page-size",
+				Usage: "`Number` of keys requested with each ListObjectsV2 request, up to 1000",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("ls requires s3://bucket/prefix")
+			}
+			bucket, prefix, err := parseS3URI(c.Args().Get(0))
+			if err != nil {
+				return err
+			}
+			if pageSize := c.Int("page-size"); pageSize < 0 || pageSize > 1000 {
+				return fmt.Errorf("--page-size must be between 1 and 1000: %d", pageSize)
+			}
+			format, err := outputFormat(c)
+			if err != nil {
+				return err
+			}
+			s3, err := newClient(c)
+			if err != nil {
+				return err
+			}
+			input := client.ListObjectsV2Input{
+				Bucket:     bucket,
+				Prefix:     prefix,
+				StartAfter: c.String("start-after"),
+				MaxKeys:    c.Int("page-size"),
+			}
+			if !c.Bool("recursive") {
+				input.Delimiter = "/"
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			it := s3.NewObjectIterator(ctx, input)
+			if format == outputJSON {
+				return printListJSON(c, it)
+			}
+			return printList(c, os.Stdout, it, prefix)
+		},
+	}
+}
+
+// listOutput is JSON output of ls command.
+type listOutput struct {
+	CommonPrefixes []client.CommonPrefix
+	Contents       []client.Object
+	TotalObjects   *int   `json:",omitempty"`
+	TotalSize      *int64 `json:",omitempty"`
+}
+
+// printListJSON lists all entries of it and prints them as JSON.
+func printListJSON(c *cli.Context, it *client.ObjectIterator) error {
+	output := listOutput{CommonPrefixes: []client.CommonPrefix{}, Contents: []client.Object{}}
+	var total int64
+	for it.Next() {
+		if prefix := it.Prefix(); prefix != "" {
+			output.CommonPrefixes = append(output.CommonPrefixes, client.CommonPrefix{Prefix: prefix})
+			continue
+		}
+		output.Contents = append(output.Contents, it.Object())
+		total += it.Object().Size
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if c.Bool("summarize") {
+		count := len(output.Contents)
+		output.TotalObjects = &count
+		output.TotalSize = &total
+	}
+	return printJSON(os.Stdout, output)
+}
+
+// printList prints entries of it as text while listing them, so that a large bucket is shown page by page.
+// Without --recursive, keys are shown relative to the "directory" of prefix.
+func printList(c *cli.Context, w io.Writer, it *client.ObjectIterator, prefix string) error {
+	base := ""
+	if !c.Bool("recursive") {
+		base = prefix[:strings.LastIndex(prefix, "/")+1]
+	}
+	humanReadable := c.Bool("human-readable")
+	var count int
+	var total int64
+	for it.Next() {
+		if p := it.Prefix(); p != "" {
+			printPrefix(w, strings.TrimPrefix(p, base))
+			continue
+		}
+		object := it.Object()
+		object.Key = strings.TrimPrefix(object.Key, base)
+		printObject(w, object, humanReadable)
+		count++
+		total += object.Size
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if c.Bool("summarize") {
+		size := fmt.Sprint(total)
+		if humanReadable {
+			size = formatSize(total)
+		}
+		fmt.Fprintf(w, "\nTotal Objects: %d\n   Total Size: %s\n", count, size)
+	}
+	return nil
+}
+
+func printPrefix(w io.Writer, prefix string) {
+	fmt.Fprintf(w, "%19s %10s %s\n", "", "PRE", prefix)
+}
+
+func printObject(w io.Writer, object client.Object, humanReadable bool) {
+	size := fmt.Sprint(object.Size)
+	if humanReadable {
+		size = formatSize(object.Size)
+	}
+	fmt.Fprintf(w, "%s %10s %s\n", object.LastModified.In(time.Local).Format("2006-01-02 15:04:05"), size, object.Key)
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/hikaru7719/s3go/client"
+	"github.com/hikaru7719/s3go/config"
+	"github.com/hikaru7719/s3go/endpoint"
 	"github.com/hikaru7719/s3go/signature"
 	"github.com/hikaru7719/s3go/uploader"
 	"github.com/urfave/cli"
@@ -23,38 +32,302 @@ func main() {
 func New() *cli.App {
 	app := cli.NewApp()
 	app.Name = "s3go"
-	app.Usage = "Upload some file to AWS S3"
+	app.Usage = "Transfer files to and from AWS S3"
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
-			Name:  "file, f",
-			Value: "",
-			Usage: "`File` to upload to S3",
+			Name:  "endpoint",
+			Value: config.Default.S3Endpoint(),
+			Usage: "`URL` of S3 compatible storage like http://localhost:9000",
+		},
+		cli.BoolFlag{
+			Name:  "path-style",
+			Usage: "Address bucket as endpoint/bucket/key instead of bucket.endpoint/key",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Value: config.Default.AWSRegion(),
+			Usage: "AWS `Region` of the bucket",
+		},
+		cli.BoolFlag{
+			Name:  "dualstack",
+			Usage: "Use the dualstack (IPv4 and IPv6) endpoint",
+		},
+		cli.BoolFlag{
+			Name:  "fips",
+			Usage: "Use the FIPS endpoint",
 		},
 		cli.StringFlag{
-			Name:  "bucket, b",
+			Name:   "profile",
+			EnvVar: "AWS_PROFILE",
+			Usage:  "Use credentials and region of `Profile` in ~/.aws/credentials and ~/.aws/config",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Value: outputText,
+			Usage: "Output `Format`, text or json",
+		},
+		cli.IntFlag{
+			Name:  "max-attempts",
+			Value: 5,
+			Usage: "Maximum `Number` of attempts for each request",
+		},
+		cli.DurationFlag{
+			Name:  "retry-base-delay",
+			Value: 100 * time.Millisecond,
+			Usage: "`Delay` before the first retry, doubled on every retry",
+		},
+		cli.DurationFlag{
+			Name:  "retry-max-delay",
+			Value: 20 * time.Second,
+			Usage: "Maximum `Delay` between retries",
+		},
+	}
+	app.Commands = []cli.Command{
+		cpCommand(),
+		mvCommand(),
+		syncCommand(),
+		lsCommand(),
+		rmCommand(),
+		headCommand(),
+		catCommand(),
+		presignCommand(),
+	}
+	return app
+}
+
+// transferFlags returns flags to configure uploads and downloads, shared by cp, mv and sync commands.
+func transferFlags() []cli.Flag {
+	return []cli.Flag{
+		cli.IntFlag{
+			Name:  "concurrency, c",
+			Value: 10,
+			Usage: "`Number` of parts transferred at the same time, in total over all files",
+		},
+		cli.BoolFlag{
+			Name:  "no-abort",
+			Usage: "Keep the incomplete multipart upload on S3 when the upload fails",
+		},
+		cli.StringFlag{
+			Name:  "checkpoint",
 			Value: "",
-			Usage: "`S3 bucket Name` to upload files",
+			Usage: "`File` to save upload progress to for resuming it",
+		},
+		cli.BoolFlag{
+			Name:  "resume",
+			Usage: "Resume the upload saved in the checkpoint file",
+		},
+		cli.StringFlag{
+			Name:  "multipart-threshold",
+			Value: "8MiB",
+			Usage: "Files smaller than `Size` are uploaded with a single request",
+		},
+		cli.StringFlag{
+			Name:  "part-size",
+			Value: "auto",
+			Usage: "`Size` of each part of multipart upload or ranged download, or auto to choose it from the file size",
+		},
+		cli.BoolFlag{
+			Name:  "streaming-signature",
+			Usage: "Sign uploads chunk by chunk with aws-chunked encoding instead of reading and hashing each part before sending it",
+		},
+		cli.BoolFlag{
+			Name:  "unsigned-payload",
+			Usage: "Send uploads with UNSIGNED-PAYLOAD and a checksum instead of hashing them with SHA-256, only over https",
+		},
+		cli.StringFlag{
+			Name:  "checksum-algorithm",
+			Value: string(uploader.ChecksumMD5),
+			Usage: "`Algorithm` of the checksum sent with --unsigned-payload, MD5, CRC32 or CRC32C",
+		},
+		cli.BoolFlag{
+			Name:  "quiet, q",
+			Usage: "Don't show progress",
 		},
 	}
+}
 
-	app.Action = func(c *cli.Context) error {
-		var file, bucket string
-		if f := c.String("file"); f != "" {
-			file = f
+// uploadFile uploads file, or stdin when file is -, to bucket as key with a progress bar.
+func uploadFile(c *cli.Context, file, bucket, key string) error {
+	sign, err := newSignature(c)
+	if err != nil {
+		return err
+	}
+	opts, err := uploadOptions(c)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, uploader.WithKey(key))
+	var bar *progressBar
+	if !c.Bool("quiet") {
+		bar = newProgressBar(os.Stdout)
+		opts = append(opts, uploader.WithProgressListener(bar))
+	}
+	var upload *uploader.S3Upload
+	if file == "-" {
+		upload = uploader.NewFromReader(bucket, key, os.Stdin, sign, opts...)
+	} else {
+		upload, err = uploader.New(bucket, file, sign, opts...)
+		if err != nil {
+			return err
 		}
+	}
 
-		if b := c.String("bucket"); b != "" {
-			bucket = b
+	ctx, cancel := signalContext()
+	defer cancel()
+	err = upload.RunContext(ctx)
+	if bar != nil {
+		bar.Done()
+	}
+	return err
+}
+
+// uploadOptions creates uploader.Option from command line flags.
+func uploadOptions(c *cli.Context) ([]uploader.Option, error) {
+	threshold, err := parseSize(c.String("multipart-threshold"))
+	if err != nil {
+		return nil, err
+	}
+	var partSize int64
+	if p := c.String("part-size"); p != "auto" {
+		partSize, err = parseSize(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	endpoint, err := newEndpoint(c)
+	if err != nil {
+		return nil, err
+	}
+	opts := []uploader.Option{
+		uploader.WithEndpoint(endpoint),
+		uploader.WithMultipartThreshold(threshold),
+		uploader.WithPartSize(partSize),
+		uploader.WithConcurrency(c.Int("concurrency")),
+		uploader.WithRetryPolicy(retryPolicy(c)),
+		uploader.WithAbortOnFailure(!c.Bool("no-abort")),
+	}
+	if checkpoint := c.String("checkpoint"); checkpoint != "" {
+		opts = append(opts, uploader.WithCheckpoint(checkpoint))
+	}
+	if c.Bool("streaming-signature") {
+		opts = append(opts, uploader.WithStreamingSignature())
+	}
+	if c.Bool("unsigned-payload") {
+		if c.Bool("streaming-signature") {
+			return nil, errors.New("--unsigned-payload can't be used with --streaming-signature")
+		}
+		algorithm, err := checksumAlgorithm(c.String("checksum-algorithm"))
+		if err != nil {
+			return nil, err
 		}
+		if endpoint.Scheme != "https" {
+			log.Println("--unsigned-payload is ignored over http, uploads are signed with SHA-256")
+		}
+		opts = append(opts, uploader.WithUnsignedPayload(algorithm))
+	}
+	if c.Bool("resume") {
+		if c.String("checkpoint") == "" {
+			return nil, errors.New("--resume requires --checkpoint")
+		}
+		opts = append(opts, uploader.WithResume())
+	}
+	return opts, nil
+}
+
+// checksumAlgorithm parses --checksum-algorithm flag.
+func checksumAlgorithm(name string) (uploader.ChecksumAlgorithm, error) {
+	algorithm := uploader.ChecksumAlgorithm(strings.ToUpper(name))
+	switch algorithm {
+	case uploader.ChecksumMD5, uploader.ChecksumCRC32, uploader.ChecksumCRC32C:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("checksum algorithm must be MD5, CRC32 or CRC32C: %s", name)
+	}
+}
+
+// retryPolicy creates uploader.RetryPolicy from --max-attempts, --retry-base-delay and --retry-max-delay flags.
+func retryPolicy(c *cli.Context) uploader.RetryPolicy {
+	retry := uploader.DefaultRetryPolicy()
+	retry.MaxAttempts = c.GlobalInt("max-attempts")
+	retry.BaseDelay = c.GlobalDuration("retry-base-delay")
+	retry.MaxDelay = c.GlobalDuration("retry-max-delay")
+	return retry
+}
 
-		fmt.Println(file, bucket)
-		sign := signature.New()
-		uploader, err := uploader.New(bucket, file, sign)
+// newClient creates client.Client configured by the global flags.
+func newClient(c *cli.Context) (*client.Client, error) {
+	sign, err := newSignature(c)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := newEndpoint(c)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(sign, client.WithEndpoint(endpoint), client.WithRetryPolicy(retryPolicy(c))), nil
+}
+
+// awsConfig returns config.Config of --profile flag, or of environment variables without it.
+// --region flag overrides the region, and us-east-1 is used when the region is not given anywhere.
+func awsConfig(c *cli.Context) (*config.Config, error) {
+	cfg := config.Default
+	if profile := c.GlobalString("profile"); profile != "" {
+		var err error
+		cfg, err = config.LoadProfile(profile)
 		if err != nil {
-			return nil
+			return nil, err
 		}
+	}
+	resolved := *cfg
+	if region := c.GlobalString("region"); region != "" {
+		resolved.Region = region
+	}
+	if resolved.Region == "" {
+		resolved.Region = "us-east-1"
+	}
+	return &resolved, nil
+}
 
-		return uploader.Run()
+// newEndpoint creates endpoint.Endpoint from --endpoint and --path-style flags.
+// Without --endpoint, AWS S3 endpoint is resolved from the region, --dualstack and --fips flags.
+func newEndpoint(c *cli.Context) (*endpoint.Endpoint, error) {
+	cfg, err := awsConfig(c)
+	if err != nil {
+		return nil, err
 	}
-	return app
+	pathStyle := c.GlobalBool("path-style") || cfg.S3PathStyle()
+	if e := c.GlobalString("endpoint"); e != "" {
+		return endpoint.Parse(e, pathStyle)
+	}
+	return endpoint.Resolve(cfg.AWSRegion(), endpoint.Options{
+		DualStack: c.GlobalBool("dualstack"),
+		FIPS:      c.GlobalBool("fips"),
+		PathStyle: pathStyle,
+	})
+}
+
+// newSignature creates signature.Signature for the credentials and the region of awsConfig.
+func newSignature(c *cli.Context) (*signature.Signature, error) {
+	cfg, err := awsConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	return signature.NewFromConfig(cfg), nil
+}
+
+// signalContext returns context canceled when s3go receives SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigChan:
+			log.Println("interrupted, canceling requests")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+	return ctx, cancel
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/urfave/cli"
+)
+
+func headCommand() cli.Command {
+	return cli.Command{
+		Name:      "head",
+		Usage:     "Show metadata of an object",
+		ArgsUsage: "s3://bucket/key",
+		Action: func(c *cli.Context) error {
+			bucket, key, err := objectArg(c)
+			if err != nil {
+				return err
+			}
+			s3, err := newClient(c)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			info, err := s3.HeadObject(ctx, bucket, key)
+			if err != nil {
+				return err
+			}
+			return printOutput(c, os.Stdout, info, func(w io.Writer) {
+				printObjectInfo(w, info)
+			})
+		},
+	}
+}
+
+func printObjectInfo(w io.Writer, info *client.ObjectInfo) {
+	fmt.Fprintf(w, "Key:           %s\n", info.Key)
+	fmt.Fprintf(w, "Size:          %d (%s)\n", info.Size, formatSize(info.Size))
+	fmt.Fprintf(w, "ETag:          %s\n", info.ETag)
+	fmt.Fprintf(w, "Last-Modified: %s\n", info.LastModified.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Content-Type:  %s\n", info.ContentType)
+	names := make([]string, 0, len(info.Metadata))
+	for name := range info.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "x-amz-meta-%s: %s\n", name, info.Metadata[name])
+	}
+}
+
+func catCommand() cli.Command {
+	return cli.Command{
+		Name:      "cat",
+		Usage:     "Write data of an object to stdout",
+		ArgsUsage: "s3://bucket/key",
+		Action: func(c *cli.Context) error {
+			bucket, key, err := objectArg(c)
+			if err != nil {
+				return err
+			}
+			s3, err := newClient(c)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			out, err := s3.GetObject(ctx, client.GetObjectInput{Bucket: bucket, Key: key})
+			if err != nil {
+				return err
+			}
+			defer out.Body.Close()
+			_, err = io.Copy(os.Stdout, out.Body)
+			return err
+		},
+	}
+}
+
+// objectArg returns the bucket and the key of the only argument s3://bucket/key.
+func objectArg(c *cli.Context) (bucket, key string, err error) {
+	if c.NArg() != 1 {
+		return "", "", fmt.Errorf("%s requires s3://bucket/key", c.Command.Name)
+	}
+	bucket, key, err = parseS3URI(c.Args().Get(0))
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" {
+		return "", "", errors.New("object key is required")
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli"
+)
+
+// Formats of --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat returns --output flag, which is outputText or outputJSON.
+func outputFormat(c *cli.Context) (string, error) {
+	switch format := c.GlobalString("output"); format {
+	case outputText, outputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("output format must be %s or %s: %s", outputText, outputJSON, format)
+	}
+}
+
+// printOutput writes value as indented JSON with --output json, or calls text with w otherwise.
+func printOutput(c *cli.Context, w io.Writer, value interface{}, text func(w io.Writer)) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	if format == outputText {
+		text(w)
+		return nil
+	}
+	return printJSON(w, value)
+}
+
+// printJSON writes value as indented JSON. URLs and keys are written without escaping &, < and >.
+func printJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
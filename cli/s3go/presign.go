@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/urfave/cli"
+)
+
+func presignCommand() cli.Command {
+	return cli.Command{
+		Name:      "presign",
+		Usage:     "Create a URL which can be requested without credentials until it expires",
+		ArgsUsage: "s3://bucket/key",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "method",
+				Value: "GET",
+				Usage: "HTTP `Method` the URL is signed for, GET, PUT, HEAD or DELETE",
+			},
+			cli.DurationFlag{
+				Name:  "expires",
+				Value: time.Hour,
+				Usage: "`Duration` for which the URL is valid, up to 168h",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			bucket, key, err := objectArg(c)
+			if err != nil {
+				return err
+			}
+			e, err := newEndpoint(c)
+			if err != nil {
+				return err
+			}
+			cfg, err := awsConfig(c)
+			if err != nil {
+				return err
+			}
+			if cfg.AWSAccessKeyID() == "" || cfg.AWSSecretAccessKey() == "" {
+				return errors.New("presign requires AWS credentials")
+			}
+			sign := signature.NewFromConfig(cfg)
+			method := strings.ToUpper(c.String("method"))
+			expires := c.Duration("expires")
+			url, err := sign.Presign(method, e.URL(bucket, key, ""), expires)
+			if err != nil {
+				return err
+			}
+			output := presignOutput{URL: url, Method: method, Expires: time.Now().Add(expires).UTC().Truncate(time.Second)}
+			return printOutput(c, os.Stdout, output, func(w io.Writer) {
+				fmt.Fprintln(w, url)
+			})
+		},
+	}
+}
+
+// presignOutput is JSON output of presign command.
+type presignOutput struct {
+	URL     string
+	Method  string
+	Expires time.Time
+}
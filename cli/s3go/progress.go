@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hikaru7719/s3go/uploader"
+)
+
+const (
+	barWidth        = 30
+	barInterval     = 100 * time.Millisecond
+	logLineInterval = 5 * time.Second
+)
+
+// progressBar shows uploader.Progress as a progress bar on terminal.
+// When stdout is not a terminal, it logs a line periodically instead.
+type progressBar struct {
+	out      *os.File
+	tty      bool
+	interval time.Duration
+	last     time.Time
+	drawn    bool
+}
+
+func newProgressBar(out *os.File) *progressBar {
+	tty := isTerminal(out)
+	interval := logLineInterval
+	if tty {
+		interval = barInterval
+	}
+	return &progressBar{out: out, tty: tty, interval: interval}
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// OnProgress implements uploader.ProgressListener
+func (b *progressBar) OnProgress(p uploader.Progress) {
+	finished := p.TotalParts > 0 && p.CompletedParts == p.TotalParts
+	if !finished && time.Since(b.last) < b.interval {
+		return
+	}
+	b.last = time.Now()
+
+	if p.TotalBytes < 0 {
+		// The length of stdin is unknown until it is read to the end.
+		b.print("", fmt.Sprintf("%s, %d parts, %s/s",
+			formatSize(p.SentBytes), p.CompletedParts, formatSize(int64(p.Throughput))))
+		return
+	}
+
+	percent := 100.0
+	if p.TotalBytes > 0 {
+		percent = float64(p.SentBytes) / float64(p.TotalBytes) * 100
+	}
+	filled := int(percent / 100 * barWidth)
+	bar := "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "] "
+	b.print(bar, fmt.Sprintf("%5.1f%% %s/%s, %d/%d parts, %s/s, ETA %s",
+		percent, formatSize(p.SentBytes), formatSize(p.TotalBytes),
+		p.CompletedParts, p.TotalParts, formatSize(int64(p.Throughput)), formatETA(p.ETA)))
+}
+
+func (b *progressBar) print(bar, status string) {
+	if !b.tty {
+		log.Printf("uploaded %s", status)
+		return
+	}
+	fmt.Fprintf(b.out, "\r%s%s\033[K", bar, status)
+	b.drawn = true
+}
+
+// Done moves the cursor to the next line after the progress bar.
+func (b *progressBar) Done() {
+	if b.drawn {
+		fmt.Fprintln(b.out)
+	}
+}
+
+func formatETA(eta time.Duration) string {
+	if eta <= 0 {
+		return "--"
+	}
+	return eta.Round(time.Second).String()
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/urfave/cli"
+)
+
+func rmCommand() cli.Command {
+	return cli.Command{
+		Name:      "rm",
+		Usage:     "Delete an object, or objects under a prefix",
+		ArgsUsage: "s3://bucket/key",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "recursive, r",
+				Usage: "Delete all objects under the prefix",
+			},
+			cli.StringSliceFlag{
+				Name:  "include",
+				Usage: "Delete only objects matching `Pattern`, which is matched against the key relative to the prefix or the base name",
+			},
+			cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Don't delete objects matching `Pattern`, which is matched against the key relative to the prefix or the base name",
+			},
+			cli.BoolFlag{
+				Name:  "dryrun",
+				Usage: "Show what would be deleted without deleting it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Bool("recursive") {
+				if c.NArg() != 1 {
+					return errors.New("rm --recursive requires s3://bucket/prefix")
+				}
+				bucket, prefix, err := parseS3URI(c.Args().Get(0))
+				if err != nil {
+					return err
+				}
+				return removePrefix(c, bucket, keyPrefix(prefix))
+			}
+			if len(c.StringSlice("include")) > 0 || len(c.StringSlice("exclude")) > 0 {
+				return errors.New("--include and --exclude require --recursive")
+			}
+			bucket, key, err := objectArg(c)
+			if err != nil {
+				return err
+			}
+			uri := c.Args().Get(0)
+			if c.Bool("dryrun") {
+				return printOutput(c, os.Stdout, rmOutput{Deleted: []string{uri}, DryRun: true}, func(w io.Writer) {
+					fmt.Fprintf(w, "(dryrun) delete: %s\n", uri)
+				})
+			}
+			s3, err := newClient(c)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signalContext()
+			defer cancel()
+			if err := s3.DeleteObject(ctx, bucket, key); err != nil {
+				return err
+			}
+			return printOutput(c, os.Stdout, rmOutput{Deleted: []string{uri}}, func(w io.Writer) {
+				fmt.Fprintf(w, "delete: %s\n", uri)
+			})
+		},
+	}
+}
+
+// rmOutput is JSON output of rm command.
+type rmOutput struct {
+	Deleted []string
+	Failed  []rmFailure `json:",omitempty"`
+	DryRun  bool        `json:",omitempty"`
+}
+
+type rmFailure struct {
+	URI   string
+	Error string
+}
+
+// removePrefix deletes objects under prefix selected by --include and --exclude flags.
+// Objects are deleted by DeleteObjects while listing them, and each batch is shown when it is deleted.
+func removePrefix(c *cli.Context, bucket, prefix string) error {
+	format, err := outputFormat(c)
+	if err != nil {
+		return err
+	}
+	filter, err := newFileFilter(c.StringSlice("include"), c.StringSlice("exclude"))
+	if err != nil {
+		return err
+	}
+	s3, err := newClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	dryrun := c.Bool("dryrun")
+	output := rmOutput{Deleted: []string{}, DryRun: dryrun}
+	batch := make([]string, 0, client.MaxDeleteKeys)
+	flush := func() {
+		var results []fileResult
+		if dryrun {
+			for _, key := range batch {
+				results = append(results, fileResult{action: "delete", uri: "s3://" + bucket + "/" + key, done: true})
+			}
+		} else {
+			results = deleteObjects(ctx, s3, bucket, batch)
+		}
+		for _, r := range results {
+			if r.done {
+				output.Deleted = append(output.Deleted, r.uri)
+			} else {
+				output.Failed = append(output.Failed, rmFailure{URI: r.uri, Error: r.err.Error()})
+			}
+			if format == outputText {
+				printDeleteResult(os.Stdout, r, dryrun)
+			}
+		}
+		batch = batch[:0]
+	}
+
+	it := s3.NewObjectIterator(ctx, client.ListObjectsV2Input{Bucket: bucket, Prefix: prefix})
+	for it.Next() {
+		key := it.Object().Key
+		if !filter.match(strings.TrimPrefix(key, prefix)) {
+			continue
+		}
+		batch = append(batch, key)
+		if len(batch) == client.MaxDeleteKeys {
+			flush()
+		}
+	}
+	flush()
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if format == outputJSON {
+		if err := printJSON(os.Stdout, output); err != nil {
+			return err
+		}
+	} else if !dryrun {
+		fmt.Fprintf(os.Stdout, "%d objects deleted, %d failed\n", len(output.Deleted), len(output.Failed))
+	}
+	if len(output.Failed) > 0 {
+		return fmt.Errorf("%d of %d objects failed", len(output.Failed), len(output.Deleted)+len(output.Failed))
+	}
+	return nil
+}
+
+func printDeleteResult(w io.Writer, r fileResult, dryrun bool) {
+	switch {
+	case dryrun:
+		fmt.Fprintf(w, "(dryrun) delete: %s\n", r.uri)
+	case r.done:
+		fmt.Fprintf(w, "delete: %s\n", r.uri)
+	default:
+		fmt.Fprintf(w, "failed to delete: %s: %v\n", r.uri, r.err)
+	}
+}
+
+// deleteObjects deletes keys in bucket with DeleteObjects and returns the result of each key.
+func deleteObjects(ctx context.Context, s3 *client.Client, bucket string, keys []string) []fileResult {
+	output, err := s3.DeleteObjects(ctx, bucket, keys)
+	deleted := make(map[string]bool, len(output.Deleted))
+	for _, object := range output.Deleted {
+		deleted[object.Key] = true
+	}
+	failed := make(map[string]error, len(output.Errors))
+	for _, e := range output.Errors {
+		failed[e.Key] = fmt.Errorf("%s: %s", e.Code, e.Message)
+	}
+	results := make([]fileResult, len(keys))
+	for i, key := range keys {
+		results[i] = fileResult{action: "delete", uri: "s3://" + bucket + "/" + key}
+		switch {
+		case deleted[key]:
+			results[i].done = true
+		case failed[key] != nil:
+			results[i].err = failed[key]
+		case err != nil:
+			results[i].err = err
+		default:
+			results[i].err = errors.New("not in the result of DeleteObjects")
+		}
+	}
+	return results
+}
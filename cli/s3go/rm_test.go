@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/request"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuth struct{}
+
+func (m *mockAuth) Authorization(method, URL, payload string, header map[string]string) string {
+	return "authorization"
+}
+
+func TestDeleteObjects(t *testing.T) {
+	cases := map[string]struct {
+		testStatus int
+		testBody   string
+		done       []bool
+		errs       []string
+	}{
+		"deleted and failed keys": {
+			testStatus: http.StatusOK,
+			testBody: `<DeleteResult>
+  <Deleted><Key>a.txt</Key></Deleted>
+  <Error><Key>b.txt</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>
+</DeleteResult>`,
+			done: []bool{true, false, false},
+			errs: []string{"", "AccessDenied: Access Denied", "not in the result of DeleteObjects"},
+		},
+		"request failed": {
+			testStatus: http.StatusForbidden,
+			testBody:   `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`,
+			done:       []bool{false, false, false},
+			errs:       []string{"AccessDenied", "AccessDenied", "AccessDenied"},
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.testStatus)
+				w.Write([]byte(tc.testBody))
+			}))
+			defer server.Close()
+			e, _ := endpoint.Parse(server.URL, true)
+			retry := request.DefaultRetryPolicy()
+			retry.MaxAttempts = 1
+			s3 := client.New(&mockAuth{}, client.WithEndpoint(e), client.WithHTTPClient(server.Client()), client.WithRetryPolicy(retry))
+
+			results := deleteObjects(context.Background(), s3, "testbucket", []string{"a.txt", "b.txt", "c.txt"})
+			assert.Equal(t, 3, len(results))
+			for i, r := range results {
+				assert.Equal(t, "delete", r.action)
+				assert.Equal(t, tc.done[i], r.done)
+				if tc.errs[i] == "" {
+					assert.NoError(t, r.err)
+				} else {
+					assert.Contains(t, r.err.Error(), tc.errs[i])
+				}
+			}
+			assert.Equal(t, "s3://testbucket/c.txt", results[2].uri)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// parseSize parses size like "8MiB" or "8MB" into bytes.
+// Like AWS CLI, KB, MB and GB are treated as powers of 1024.
+func parseSize(str string) (int64, error) {
+	str = strings.TrimSpace(str)
+	unit := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s", str)
+	}
+	return n * unit, nil
+}
+
+// formatSize formats bytes into human readable size like "1.5MiB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	size := float64(n)
+	for _, suffix := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		size /= unit
+		if size < unit || suffix == "TiB" {
+			return fmt.Sprintf("%.1f%s", size, suffix)
+		}
+	}
+	return ""
+}
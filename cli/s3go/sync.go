@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/hikaru7719/s3go/uploader"
+	"github.com/urfave/cli"
+)
+
+func syncCommand() cli.Command {
+	flags := append(transferFlags(),
+		cli.BoolFlag{
+			Name:  "delete",
+			Usage: "Delete objects under the prefix which don't exist in the directory",
+		},
+		cli.BoolFlag{
+			Name:  "dryrun",
+			Usage: "Show what would be uploaded and deleted without doing it",
+		},
+		cli.BoolFlag{
+			Name:  "checksum",
+			Usage: "Compare MD5 of files with ETag of objects instead of modification time",
+		},
+	)
+	return cli.Command{
+		Name:      "sync",
+		Usage:     "Upload new and changed files in a directory to S3",
+		ArgsUsage: "DIRECTORY s3://bucket/prefix",
+		Flags:     append(flags, dirFlags()...),
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 2 {
+				return errors.New("sync requires DIRECTORY and s3://bucket/prefix")
+			}
+			bucket, prefix, err := parseS3URI(c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+			return syncDir(c, c.Args().Get(0), bucket, keyPrefix(prefix))
+		},
+	}
+}
+
+// syncDir uploads files under dir which are missing or changed under the prefix.
+// With --delete, objects under the prefix missing in dir are deleted.
+func syncDir(c *cli.Context, dir, bucket, prefix string) error {
+	files, filter, err := walkDir(c, dir)
+	if err != nil {
+		return err
+	}
+	s3, err := newClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+	objects, err := s3.ListAllObjects(ctx, bucket, prefix)
+	if err != nil {
+		return err
+	}
+	plan, err := planSync(files, objects, prefix, filter, c.Bool("delete"), c.Bool("checksum"))
+	if err != nil {
+		return err
+	}
+	if c.Bool("dryrun") {
+		plan.print(os.Stdout, dir, bucket, prefix)
+		return nil
+	}
+
+	results, err := uploadFiles(ctx, c, dir, bucket, prefix, plan.uploads)
+	if err != nil {
+		return err
+	}
+	if len(plan.deletes) > 0 {
+		keys := make([]string, len(plan.deletes))
+		for i, object := range plan.deletes {
+			keys[i] = object.Key
+		}
+		results = append(results, deleteObjects(ctx, s3, bucket, keys)...)
+	}
+	return printSummary(os.Stdout, results)
+}
+
+// syncPlan is what sync uploads and deletes to make the prefix same as the directory.
+type syncPlan struct {
+	uploads []localFile
+	deletes []client.Object
+}
+
+// planSync compares files with objects under prefix. Objects are deleted only when deleteRemoved is true,
+// and objects not selected by filter are never deleted.
+func planSync(files []localFile, objects []client.Object, prefix string, filter *fileFilter, deleteRemoved, checksum bool) (syncPlan, error) {
+	remote := make(map[string]client.Object, len(objects))
+	for _, object := range objects {
+		remote[object.Key] = object
+	}
+	plan := syncPlan{}
+	for _, f := range files {
+		object, ok := remote[prefix+f.rel]
+		delete(remote, prefix+f.rel)
+		if ok {
+			changed, err := fileChanged(f, object, checksum)
+			if err != nil {
+				return syncPlan{}, err
+			}
+			if !changed {
+				continue
+			}
+		}
+		plan.uploads = append(plan.uploads, f)
+	}
+	if !deleteRemoved {
+		return plan, nil
+	}
+	for _, object := range objects {
+		rel := strings.TrimPrefix(object.Key, prefix)
+		if _, ok := remote[object.Key]; !ok || strings.HasSuffix(rel, "/") || !filter.match(rel) {
+			continue
+		}
+		plan.deletes = append(plan.deletes, object)
+	}
+	return plan, nil
+}
+
+// fileChanged reports whether f differs from object.
+// Files of the same size are compared by modification time, or by ETag when checksum is true.
+func fileChanged(f localFile, object client.Object, checksum bool) (bool, error) {
+	if f.size != object.Size {
+		return true, nil
+	}
+	if !checksum {
+		return f.modTime.After(object.LastModified), nil
+	}
+	etag, err := localETag(f, object.ETag)
+	if err != nil {
+		return false, err
+	}
+	return etag != object.ETag, nil
+}
+
+// localETag calculates ETag of f to compare with remote ETag.
+// The part size of a multipart upload is not known from ETag, so part sizes commonly used are tried.
+func localETag(f localFile, remote string) (string, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	parts := etagParts(remote)
+	if parts == 0 {
+		return uploader.ETag(file, f.size, 0, 0)
+	}
+	var etag string
+	for _, partSize := range partSizeCandidates(f.size, parts) {
+		etag, err = uploader.ETag(file, f.size, parts, partSize)
+		if err != nil {
+			return "", err
+		}
+		if etag == remote {
+			break
+		}
+	}
+	return etag, nil
+}
+
+// etagParts returns the number of parts of a multipart upload ETag like "xxx-3", or 0 for other ETag.
+func etagParts(etag string) int {
+	etag = strings.Trim(etag, `"`)
+	i := strings.LastIndex(etag, "-")
+	if i < 0 {
+		return 0
+	}
+	parts, err := strconv.Atoi(etag[i+1:])
+	if err != nil {
+		return 0
+	}
+	return parts
+}
+
+// partSizeCandidates returns part sizes dividing size bytes into parts.
+func partSizeCandidates(size int64, parts int) []int64 {
+	const mib = 1024 * 1024
+	auto, _ := uploader.PartSize(size, 0)
+	even := (size + int64(parts) - 1) / int64(parts)
+	candidates := []int64{auto, 8 * mib, 16 * mib, (even + mib - 1) / mib * mib}
+	result := make([]int64, 0, len(candidates))
+	seen := make(map[int64]bool)
+	for _, partSize := range candidates {
+		if partSize <= 0 || seen[partSize] || (size+partSize-1)/partSize != int64(parts) {
+			continue
+		}
+		seen[partSize] = true
+		result = append(result, partSize)
+	}
+	return result
+}
+
+func (p syncPlan) print(w io.Writer, dir, bucket, prefix string) {
+	for _, f := range p.uploads {
+		fmt.Fprintf(w, "(dryrun) upload: %s to s3://%s/%s%s\n", filepath.Join(dir, filepath.FromSlash(f.rel)), bucket, prefix, f.rel)
+	}
+	for _, object := range p.deletes {
+		fmt.Fprintf(w, "(dryrun) delete: s3://%s/%s\n", bucket, object.Key)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "same.txt"), "same")
+	writeFile(t, filepath.Join(dir, "modified.txt"), "new")
+	sum := md5.Sum([]byte("same"))
+	sameETag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+	now := time.Now()
+	files := []localFile{
+		{path: filepath.Join(dir, "new.txt"), rel: "new.txt", size: 3, modTime: now},
+		{path: filepath.Join(dir, "resized.txt"), rel: "resized.txt", size: 3, modTime: now.Add(-time.Hour)},
+		{path: filepath.Join(dir, "modified.txt"), rel: "modified.txt", size: 3, modTime: now},
+		{path: filepath.Join(dir, "same.txt"), rel: "same.txt", size: 4, modTime: now},
+	}
+	objects := []client.Object{
+		{Key: "prefix/resized.txt", Size: 5, LastModified: now},
+		{Key: "prefix/modified.txt", Size: 3, LastModified: now.Add(-time.Hour), ETag: `"other"`},
+		{Key: "prefix/same.txt", Size: 4, LastModified: now.Add(-time.Hour), ETag: sameETag},
+		{Key: "prefix/removed.txt", Size: 1, LastModified: now},
+		{Key: "prefix/removed.log", Size: 1, LastModified: now},
+		{Key: "prefix/dir/", Size: 0, LastModified: now},
+	}
+
+	cases := map[string]struct {
+		testDelete    bool
+		testChecksum  bool
+		expectUploads []string
+		expectDeletes []string
+	}{
+		"mtime":    {expectUploads: []string{"new.txt", "resized.txt", "modified.txt", "same.txt"}},
+		"checksum": {testChecksum: true, expectUploads: []string{"new.txt", "resized.txt", "modified.txt"}},
+		"delete":   {testDelete: true, testChecksum: true, expectUploads: []string{"new.txt", "resized.txt", "modified.txt"}, expectDeletes: []string{"prefix/removed.txt"}},
+	}
+
+	filter, _ := newFileFilter(nil, []string{"*.log"})
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			plan, err := planSync(files, objects, "prefix/", filter, tc.testDelete, tc.testChecksum)
+			assert.NoError(t, err)
+			uploads := make([]string, 0, len(plan.uploads))
+			for _, f := range plan.uploads {
+				uploads = append(uploads, f.rel)
+			}
+			var deletes []string
+			for _, object := range plan.deletes {
+				deletes = append(deletes, object.Key)
+			}
+			assert.Equal(t, tc.expectUploads, uploads)
+			assert.Equal(t, tc.expectDeletes, deletes)
+		})
+	}
+}
+
+func TestETagParts(t *testing.T) {
+	assert.Equal(t, 0, etagParts(`"d41d8cd98f00b204e9800998ecf8427e"`))
+	assert.Equal(t, 3, etagParts(`"d41d8cd98f00b204e9800998ecf8427e-3"`))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// isS3URI reports whether uri is an S3 URI rather than a local path.
+func isS3URI(uri string) bool {
+	return strings.HasPrefix(uri, "s3://")
+}
+
+// parseS3URI splits URI like s3://bucket/prefix/key into bucket and key.
+func parseS3URI(uri string) (bucket, key string, err error) {
+	if !isS3URI(uri) {
+		return "", "", fmt.Errorf("S3 URI must start with s3://: %s", uri)
+	}
+	path := strings.TrimPrefix(uri, "s3://")
+	i := strings.Index(path, "/")
+	if i < 0 {
+		bucket = path
+	} else {
+		bucket, key = path[:i], path[i+1:]
+	}
+	if bucket == "" {
+		return "", "", fmt.Errorf("S3 URI has no bucket: %s", uri)
+	}
+	return bucket, key, nil
+}
+
+// objectKey returns the key to upload file to.
+// When key is empty or ends with "/", the base name of file is appended like cp command.
+func objectKey(key, file string) (string, error) {
+	if key != "" && !strings.HasSuffix(key, "/") {
+		return key, nil
+	}
+	if file == "-" {
+		return "", fmt.Errorf("object key is required to upload from stdin")
+	}
+	return key + filepath.Base(file), nil
+}
+
+// localPath returns the file to download key to.
+// When file is a directory or ends with a path separator, the base name of key is appended to it.
+func localPath(file, key string) (string, error) {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return "", fmt.Errorf("object key is required to download: %s", key)
+	}
+	if info, err := os.Stat(file); (err == nil && info.IsDir()) || strings.HasSuffix(file, string(filepath.Separator)) {
+		return filepath.Join(file, path.Base(key)), nil
+	}
+	return file, nil
+}
+
+// copyKey returns the key to copy srcKey to.
+// When dstKey is empty or ends with "/", the base name of srcKey is appended to it.
+func copyKey(dstKey, srcKey string) (string, error) {
+	if srcKey == "" || strings.HasSuffix(srcKey, "/") {
+		return "", fmt.Errorf("object key is required to copy: %s", srcKey)
+	}
+	if dstKey != "" && !strings.HasSuffix(dstKey, "/") {
+		return dstKey, nil
+	}
+	return dstKey + path.Base(srcKey), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseS3URI(t *testing.T) {
+	cases := map[string]struct {
+		testURI      string
+		expectBucket string
+		expectKey    string
+		expectError  bool
+	}{
+		"bucket only":       {testURI: "s3://bucket", expectBucket: "bucket"},
+		"bucket with slash": {testURI: "s3://bucket/", expectBucket: "bucket"},
+		"key":               {testURI: "s3://bucket/dir/file name.txt", expectBucket: "bucket", expectKey: "dir/file name.txt"},
+		"prefix":            {testURI: "s3://bucket/dir/", expectBucket: "bucket", expectKey: "dir/"},
+		"no scheme":         {testURI: "bucket/key", expectError: true},
+		"no bucket":         {testURI: "s3:///key", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			bucket, key, err := parseS3URI(tc.testURI)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectBucket, bucket)
+			assert.Equal(t, tc.expectKey, key)
+		})
+	}
+}
+
+func TestObjectKey(t *testing.T) {
+	cases := map[string]struct {
+		testKey     string
+		testFile    string
+		expectKey   string
+		expectError bool
+	}{
+		"no key":     {testKey: "", testFile: "../data/earth.jpg", expectKey: "earth.jpg"},
+		"prefix":     {testKey: "images/", testFile: "earth.jpg", expectKey: "images/earth.jpg"},
+		"key":        {testKey: "images/planet.jpg", testFile: "earth.jpg", expectKey: "images/planet.jpg"},
+		"stdin":      {testKey: "dump.sql", testFile: "-", expectKey: "dump.sql"},
+		"stdin only": {testKey: "dumps/", testFile: "-", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			key, err := objectKey(tc.testKey, tc.testFile)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectKey, key)
+		})
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := map[string]struct {
+		testFile    string
+		testKey     string
+		expectFile  string
+		expectError bool
+	}{
+		"file":          {testFile: "local.jpg", testKey: "images/earth.jpg", expectFile: "local.jpg"},
+		"directory":     {testFile: dir, testKey: "images/earth.jpg", expectFile: filepath.Join(dir, "earth.jpg")},
+		"trailing /":    {testFile: "new/", testKey: "images/earth.jpg", expectFile: filepath.Join("new", "earth.jpg")},
+		"prefix":        {testFile: "local.jpg", testKey: "images/", expectError: true},
+		"bucket itself": {testFile: "local.jpg", testKey: "", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			file, err := localPath(tc.testFile, tc.testKey)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectFile, file)
+		})
+	}
+}
+
+func TestCopyKey(t *testing.T) {
+	cases := map[string]struct {
+		testDstKey  string
+		testSrcKey  string
+		expectKey   string
+		expectError bool
+	}{
+		"key":           {testDstKey: "backup/planet.jpg", testSrcKey: "images/earth.jpg", expectKey: "backup/planet.jpg"},
+		"prefix":        {testDstKey: "backup/", testSrcKey: "images/earth.jpg", expectKey: "backup/earth.jpg"},
+		"bucket":        {testDstKey: "", testSrcKey: "images/earth.jpg", expectKey: "earth.jpg"},
+		"source prefix": {testDstKey: "backup/", testSrcKey: "images/", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			key, err := copyKey(tc.testDstKey, tc.testSrcKey)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectKey, key)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Policies for symbolic links found while walking a directory.
+const (
+	symlinkFollow = "follow"
+	symlinkSkip   = "skip"
+)
+
+// localFile is a regular file found under the directory to upload.
+type localFile struct {
+	// path is the path to open the file.
+	path string
+	// rel is the slash separated path relative to the directory, which is appended to the key prefix.
+	rel     string
+	size    int64
+	modTime time.Time
+}
+
+// fileFilter selects files by glob patterns matched against the relative path or the base name of a file.
+// A file is selected when it matches any include pattern, or no include pattern is given, and no exclude pattern.
+type fileFilter struct {
+	include []string
+	exclude []string
+}
+
+func newFileFilter(include, exclude []string) (*fileFilter, error) {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return &fileFilter{include: include, exclude: exclude}, nil
+}
+
+func (f *fileFilter) match(rel string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, rel) {
+		return false
+	}
+	return !matchAny(f.exclude, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFiles returns regular files under root selected by filter in lexical order.
+// Symbolic links are followed or skipped by symlinks policy. A linked directory is walked only once
+// so that a link to its ancestor doesn't loop.
+func walkFiles(root, symlinks string, filter *fileFilter) ([]localFile, error) {
+	if symlinks != symlinkFollow && symlinks != symlinkSkip {
+		return nil, fmt.Errorf("symlink policy must be %s or %s: %s", symlinkFollow, symlinkSkip, symlinks)
+	}
+	w := &walker{symlinks: symlinks, filter: filter, visited: make(map[string]bool)}
+	if err := w.walk(root, ""); err != nil {
+		return nil, err
+	}
+	return w.files, nil
+}
+
+type walker struct {
+	symlinks string
+	filter   *fileFilter
+	visited  map[string]bool
+	files    []localFile
+}
+
+// walk adds files under dir, whose path relative to the root is rel.
+func (w *walker) walk(dir, rel string) error {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if w.visited[real] {
+		return nil
+	}
+	w.visited[real] = true
+
+	return filepath.Walk(real, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		r, err := filepath.Rel(real, p)
+		if err != nil {
+			return err
+		}
+		r = path.Join(rel, filepath.ToSlash(r))
+		if info.Mode()&os.ModeSymlink != 0 {
+			if w.symlinks == symlinkSkip {
+				return nil
+			}
+			info, err = os.Stat(p)
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return w.walk(p, r)
+			}
+		}
+		if !info.Mode().IsRegular() || !w.filter.match(r) {
+			return nil
+		}
+		w.files = append(w.files, localFile{path: p, rel: r, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+}
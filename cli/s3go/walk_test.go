@@ -0,0 +1,102 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileFilter(t *testing.T) {
+	cases := map[string]struct {
+		testInclude []string
+		testExclude []string
+		testPath    string
+		expectMatch bool
+	}{
+		"no pattern":          {testPath: "a/b.txt", expectMatch: true},
+		"include base name":   {testInclude: []string{"*.txt"}, testPath: "a/b.txt", expectMatch: true},
+		"not included":        {testInclude: []string{"*.txt"}, testPath: "a/b.log", expectMatch: false},
+		"exclude path":        {testExclude: []string{"a/*"}, testPath: "a/b.txt", expectMatch: false},
+		"exclude other":       {testExclude: []string{"a/*"}, testPath: "c/b.txt", expectMatch: true},
+		"exclude wins":        {testInclude: []string{"*.txt"}, testExclude: []string{"b.*"}, testPath: "a/b.txt", expectMatch: false},
+		"include nested path": {testInclude: []string{"a/*/c.txt"}, testPath: "a/b/c.txt", expectMatch: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			filter, err := newFileFilter(tc.testInclude, tc.testExclude)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectMatch, filter.match(tc.testPath))
+		})
+	}
+
+	_, err := newFileFilter([]string{"[a-"}, nil)
+	assert.Error(t, err)
+}
+
+func TestWalkFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	other, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(other)
+
+	writeFile(t, filepath.Join(root, "a.txt"), "a")
+	writeFile(t, filepath.Join(root, "dir", "b.txt"), "bb")
+	writeFile(t, filepath.Join(root, "dir", "c.log"), "ccc")
+	writeFile(t, filepath.Join(other, "d.txt"), "dddd")
+	if err := os.Symlink(filepath.Join(root, "a.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, filepath.Join(root, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "dir", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		testSymlinks string
+		testExclude  []string
+		expectFiles  []string
+	}{
+		"follow":  {testSymlinks: symlinkFollow, expectFiles: []string{"a.txt", "dir/b.txt", "dir/c.log", "link.txt", "linkdir/d.txt"}},
+		"skip":    {testSymlinks: symlinkSkip, expectFiles: []string{"a.txt", "dir/b.txt", "dir/c.log"}},
+		"exclude": {testSymlinks: symlinkSkip, testExclude: []string{"*.log"}, expectFiles: []string{"a.txt", "dir/b.txt"}},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			filter, _ := newFileFilter(nil, tc.testExclude)
+			files, err := walkFiles(root, tc.testSymlinks, filter)
+			assert.NoError(t, err)
+			rels := make([]string, 0, len(files))
+			for _, f := range files {
+				rels = append(rels, f.rel)
+			}
+			assert.Equal(t, tc.expectFiles, rels)
+		})
+	}
+
+	_, err = walkFiles(root, "invalid", &fileFilter{})
+	assert.Error(t, err)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,131 @@
+// Package client provides S3 operations on buckets and objects.
+// Uploading an object is provided by the uploader package.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/request"
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/hikaru7719/s3go/time"
+)
+
+// Signature is interface
+type Signature interface {
+	Authorization(method, URL, payload string, header map[string]string) string
+}
+
+// Option configures Client
+type Option func(*Client)
+
+// WithEndpoint sets the endpoint to send requests to, such as S3 compatible storage.
+func WithEndpoint(e *endpoint.Endpoint) Option {
+	return func(c *Client) {
+		c.endpoint = e
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(policy request.RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithHTTPClient sets http.Client to send requests with.
+// It must not follow redirects, since S3 redirects a request to the region of the bucket.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// Client sends signed requests to S3. It is safe for concurrent use.
+type Client struct {
+	// mutex guards endpoint and signature switched by followRegion.
+	mutex     sync.Mutex
+	endpoint  *endpoint.Endpoint
+	signature Signature
+	client    *http.Client
+	retry     request.RetryPolicy
+}
+
+// New returns Client signing requests with signature.
+func New(signature Signature, opts ...Option) *Client {
+	c := &Client{
+		endpoint:  endpoint.Default,
+		signature: signature,
+		client:    request.NewHTTPClient(),
+		retry:     request.DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	return request.Do(ctx, c.client, c.retry, newRequest, c.followRegion)
+}
+
+// followRegion switches the endpoint and the signature to region and reports whether it switched.
+func (c *Client) followRegion(region string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, sig, ok := request.FollowRegion(c.endpoint, c.signature, region)
+	c.endpoint, c.signature = e, sig
+	return ok
+}
+
+// newRequest returns a signed request for key in bucket. When key is empty, the request is for the bucket.
+// header is added to the request and signed.
+func (c *Client) newRequest(method, bucket, key string, query url.Values, header map[string]string, body []byte) (*http.Request, error) {
+	c.mutex.Lock()
+	e, sig := c.endpoint, c.signature
+	c.mutex.Unlock()
+
+	u := e.URL(bucket, key, encodeQuery(query))
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Add("x-amz-date", time.Default.Now())
+	req.Header.Add("Host", e.BucketHost(bucket))
+	req.Header.Add("x-amz-content-sha256", hashSHA256(body))
+	for key, value := range header {
+		req.Header.Add(key, value)
+	}
+	headerMap := make(map[string]string)
+	for key := range req.Header {
+		headerMap[key] = req.Header.Get(key)
+	}
+	req.Header.Add("Authorization", sig.Authorization(method, u, string(body), headerMap))
+	return req, nil
+}
+
+// encodeQuery encodes query in the same way as the canonical request of the signature.
+func encodeQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, signature.URIEncode(key, true)+"="+signature.URIEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+func hashSHA256(payload []byte) string {
+	hash := sha256.Sum256(payload)
+	return hex.EncodeToString(hash[:])
+}
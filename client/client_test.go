@@ -0,0 +1,34 @@
+package client
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAuth struct{}
+
+func (m *mockAuth) Authorization(method, URL, payload string, header map[string]string) string {
+	return "testAuthorization"
+}
+
+func newTestClient(server *httptest.Server) *Client {
+	e, _ := endpoint.Parse(server.URL, true)
+	return New(&mockAuth{}, WithEndpoint(e), WithHTTPClient(server.Client()))
+}
+
+func TestNewRequest(t *testing.T) {
+	c := New(&mockAuth{}, WithEndpoint(&endpoint.Endpoint{Scheme: "https", Host: "testhost"}))
+	query := url.Values{}
+	query.Set("prefix", "dir/a b")
+	query.Set("list-type", "2")
+	req, err := c.newRequest("GET", "testbucket", "", query, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://testbucket.testhost/?list-type=2&prefix=dir%2Fa%20b", req.URL.String())
+	assert.Equal(t, "testbucket.testhost", req.Header.Get("Host"))
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", req.Header.Get("x-amz-content-sha256"))
+	assert.Equal(t, "testAuthorization", req.Header.Get("Authorization"))
+}
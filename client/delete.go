@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+)
+
+// DeleteObject is request to delete the object of key in bucket.
+// Deleting a key which doesn't exist succeeds.
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	res, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest("DELETE", bucket, key, nil, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+// MaxDeleteKeys is the maximum number of keys which S3 deletes with a DeleteObjects request.
+const MaxDeleteKeys = 1000
+
+// DeletedObject is an object deleted by DeleteObjects.
+type DeletedObject struct {
+	Key string
+}
+
+// DeleteError is the error of a key which DeleteObjects failed to delete.
+type DeleteError struct {
+	Key     string
+	Code    string
+	Message string
+}
+
+func (e DeleteError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Key, e.Code, e.Message)
+}
+
+// DeleteObjectsOutput is the result of each key of DeleteObjects.
+type DeleteObjectsOutput struct {
+	Deleted []DeletedObject
+	Errors  []DeleteError
+}
+
+type deleteRequest struct {
+	XMLName xml.Name       `xml:"Delete"`
+	Objects []deleteObject `xml:"Object"`
+	Quiet   bool
+}
+
+type deleteObject struct {
+	Key string
+}
+
+type deleteResult struct {
+	Deleted []DeletedObject `xml:"Deleted"`
+	Errors  []DeleteError   `xml:"Error"`
+}
+
+// DeleteObjects deletes keys in bucket with multi-object delete requests of MaxDeleteKeys keys at most.
+// Keys which S3 failed to delete are returned in Errors of the output instead of error.
+// When a request fails, the output has the results of the requests before it.
+func (c *Client) DeleteObjects(ctx context.Context, bucket string, keys []string) (*DeleteObjectsOutput, error) {
+	output := &DeleteObjectsOutput{}
+	for start := 0; start < len(keys); start += MaxDeleteKeys {
+		end := start + MaxDeleteKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		result, err := c.deleteObjects(ctx, bucket, keys[start:end])
+		if err != nil {
+			return output, err
+		}
+		output.Deleted = append(output.Deleted, result.Deleted...)
+		output.Errors = append(output.Errors, result.Errors...)
+	}
+	return output, nil
+}
+
+func (c *Client) deleteObjects(ctx context.Context, bucket string, keys []string) (*deleteResult, error) {
+	body := deleteRequest{Objects: make([]deleteObject, len(keys))}
+	for i, key := range keys {
+		body.Objects[i].Key = key
+	}
+	payload, err := xml.Marshal(body)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create delete request: %w", err)
+	}
+	sum := md5.Sum(payload)
+	header := map[string]string{
+		"Content-MD5":  base64.StdEncoding.EncodeToString(sum[:]),
+		"Content-Type": "application/xml",
+	}
+	query := url.Values{"delete": []string{""}}
+	res, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest("POST", bucket, "", query, header, payload)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	result := &deleteResult{}
+	if err := xml.NewDecoder(res.Body).Decode(result); err != nil {
+		return nil, xerrors.Errorf("invalid delete objects response: %w", err)
+	}
+	return result, nil
+}
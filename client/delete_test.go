@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hikaru7719/s3go/request"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDeleteObject(t *testing.T) {
+	cases := map[string]struct {
+		testStatus  int
+		testBody    string
+		expectError bool
+	}{
+		"deleted":       {testStatus: http.StatusNoContent},
+		"access denied": {testStatus: http.StatusForbidden, testBody: `<Error><Code>AccessDenied</Code></Error>`, expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.RequestURI())
+				w.WriteHeader(tc.testStatus)
+				w.Write([]byte(tc.testBody))
+			}))
+			defer server.Close()
+
+			err := newTestClient(server).DeleteObject(context.Background(), "testbucket", "dir/a b.txt")
+			assert.Equal(t, []string{"DELETE /testbucket/dir/a%20b.txt"}, requests)
+			if !tc.expectError {
+				assert.NoError(t, err)
+				return
+			}
+			var s3Err *request.S3Error
+			assert.True(t, xerrors.As(err, &s3Err))
+			assert.Equal(t, request.ErrCodeAccessDenied, s3Err.Code)
+		})
+	}
+}
+
+func TestDeleteObjects(t *testing.T) {
+	keys := make([]string, 1001)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("dir/%04d & <key>", i)
+	}
+	var batches [][]string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sum := md5.Sum(body)
+		assert.Equal(t, "POST /testbucket?delete=", r.Method+" "+r.URL.RequestURI())
+		assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), r.Header.Get("Content-MD5"))
+		var req struct {
+			Objects []struct{ Key string } `xml:"Object"`
+		}
+		assert.NoError(t, xml.Unmarshal(body, &req))
+		var batch []string
+		for _, object := range req.Objects {
+			batch = append(batch, object.Key)
+		}
+		batches = append(batches, batch)
+		if len(batches) == 1 {
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Deleted><Key>dir/0000 &amp; &lt;key&gt;</Key></Deleted>
+  <Error><Key>dir/0001 &amp; &lt;key&gt;</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>
+</DeleteResult>`))
+			return
+		}
+		w.Write([]byte(`<DeleteResult><Deleted><Key>dir/1000 &amp; &lt;key&gt;</Key></Deleted></DeleteResult>`))
+	}))
+	defer server.Close()
+
+	output, err := newTestClient(server).DeleteObjects(context.Background(), "testbucket", keys)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{keys[:1000], keys[1000:]}, batches)
+	assert.Equal(t, []DeletedObject{{Key: keys[0]}, {Key: keys[1000]}}, output.Deleted)
+	assert.Equal(t, []DeleteError{{Key: keys[1], Code: request.ErrCodeAccessDenied, Message: "Access Denied"}}, output.Errors)
+}
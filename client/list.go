@@ -0,0 +1,222 @@
+package client
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	stdtime "time"
+
+	"golang.org/x/xerrors"
+)
+
+// Object is an object listed by ListObjectsV2.
+type Object struct {
+	Key          string
+	LastModified stdtime.Time
+	// ETag is quoted as it is returned by S3.
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+// CommonPrefix is a key prefix up to the delimiter, which is shown as a directory.
+type CommonPrefix struct {
+	Prefix string
+}
+
+// ListObjectsV2Input is parameters of ListObjectsV2 request.
+type ListObjectsV2Input struct {
+	Bucket string
+	Prefix string
+	// Delimiter groups keys containing it after the prefix into CommonPrefixes.
+	Delimiter string
+	// StartAfter lists keys after it in lexical order.
+	StartAfter string
+	// MaxKeys is the maximum number of keys and common prefixes in a page. S3 uses 1000 when it is 0.
+	MaxKeys int
+	// ContinuationToken is NextContinuationToken of the previous page.
+	ContinuationToken string
+}
+
+// ListBucketResult is response XML of ListObjectsV2 request.
+type ListBucketResult struct {
+	Name                  string
+	Prefix                string
+	Delimiter             string
+	StartAfter            string
+	KeyCount              int
+	MaxKeys               int
+	EncodingType          string
+	IsTruncated           bool
+	ContinuationToken     string
+	NextContinuationToken string
+	Contents              []Object
+	CommonPrefixes        []CommonPrefix
+}
+
+// ListObjectsV2 is request to get a page of objects in the bucket.
+// Keys are requested with encoding-type=url, so that keys with characters invalid in XML can be listed,
+// and they are decoded in the result.
+func (c *Client) ListObjectsV2(ctx context.Context, input ListObjectsV2Input) (*ListBucketResult, error) {
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("encoding-type", "url")
+	if input.Prefix != "" {
+		query.Set("prefix", input.Prefix)
+	}
+	if input.Delimiter != "" {
+		query.Set("delimiter", input.Delimiter)
+	}
+	if input.StartAfter != "" {
+		query.Set("start-after", input.StartAfter)
+	}
+	if input.MaxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(input.MaxKeys))
+	}
+	if input.ContinuationToken != "" {
+		query.Set("continuation-token", input.ContinuationToken)
+	}
+	res, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest("GET", input.Bucket, "", query, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	result := &ListBucketResult{}
+	if err := xml.NewDecoder(res.Body).Decode(result); err != nil {
+		return nil, xerrors.Errorf("invalid list objects response: %w", err)
+	}
+	if err := result.decodeURL(); err != nil {
+		return nil, xerrors.Errorf("invalid list objects response: %w", err)
+	}
+	return result, nil
+}
+
+// decodeURL decodes keys and prefixes encoded by encoding-type=url.
+func (r *ListBucketResult) decodeURL() error {
+	if r.EncodingType != "url" {
+		return nil
+	}
+	var err error
+	decode := func(s *string) {
+		if err == nil {
+			*s, err = url.QueryUnescape(*s)
+		}
+	}
+	decode(&r.Prefix)
+	decode(&r.Delimiter)
+	decode(&r.StartAfter)
+	for i := range r.Contents {
+		decode(&r.Contents[i].Key)
+	}
+	for i := range r.CommonPrefixes {
+		decode(&r.CommonPrefixes[i].Prefix)
+	}
+	return err
+}
+
+// ListAllObjects lists all objects whose key starts with prefix, following pagination.
+func (c *Client) ListAllObjects(ctx context.Context, bucket, prefix string) ([]Object, error) {
+	objects := make([]Object, 0, 10)
+	it := c.NewObjectIterator(ctx, ListObjectsV2Input{Bucket: bucket, Prefix: prefix})
+	for it.Next() {
+		objects = append(objects, it.Object())
+	}
+	return objects, it.Err()
+}
+
+// ObjectIterator iterates objects and common prefixes over pages of ListObjectsV2.
+// Each page is requested when the previous one has been iterated.
+//
+//	it := c.NewObjectIterator(ctx, input)
+//	for it.Next() {
+//		object := it.Object()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ObjectIterator struct {
+	client  *Client
+	ctx     context.Context
+	input   ListObjectsV2Input
+	entries []entry
+	current entry
+	last    bool
+	err     error
+}
+
+// entry is either an object or a common prefix.
+type entry struct {
+	object Object
+	prefix string
+}
+
+func (e entry) key() string {
+	if e.prefix != "" {
+		return e.prefix
+	}
+	return e.object.Key
+}
+
+// NewObjectIterator returns ObjectIterator listing objects by input.
+func (c *Client) NewObjectIterator(ctx context.Context, input ListObjectsV2Input) *ObjectIterator {
+	return &ObjectIterator{client: c, ctx: ctx, input: input}
+}
+
+// Next advances to the next object or common prefix. It returns false at the end or on error.
+// Common prefixes and objects are iterated together in lexical order.
+func (it *ObjectIterator) Next() bool {
+	for len(it.entries) == 0 {
+		if it.last || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	it.current = it.entries[0]
+	it.entries = it.entries[1:]
+	return true
+}
+
+func (it *ObjectIterator) fetch() {
+	result, err := it.client.ListObjectsV2(it.ctx, it.input)
+	if err != nil {
+		it.err = err
+		return
+	}
+	for _, object := range result.Contents {
+		it.entries = append(it.entries, entry{object: object})
+	}
+	for _, prefix := range result.CommonPrefixes {
+		it.entries = append(it.entries, entry{prefix: prefix.Prefix})
+	}
+	sort.SliceStable(it.entries, func(i, j int) bool {
+		return it.entries[i].key() < it.entries[j].key()
+	})
+	if !result.IsTruncated {
+		it.last = true
+		return
+	}
+	if result.NextContinuationToken == "" {
+		it.err = xerrors.New("truncated list objects response has no NextContinuationToken")
+		return
+	}
+	it.input.ContinuationToken = result.NextContinuationToken
+}
+
+// Object returns the current object. It is zero value when the current entry is a common prefix.
+func (it *ObjectIterator) Object() Object {
+	return it.current.object
+}
+
+// Prefix returns the current common prefix, or "" when the current entry is an object.
+func (it *ObjectIterator) Prefix() string {
+	return it.current.prefix
+}
+
+// Err returns the error which stopped the iteration.
+func (it *ObjectIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListAllObjects(t *testing.T) {
+	var queries []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.URL.Query().Get("continuation-token") == "" {
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>testbucket</Name><Prefix>dir/</Prefix><KeyCount>1</KeyCount><MaxKeys>1</MaxKeys><IsTruncated>true</IsTruncated>
+  <NextContinuationToken>token/1=</NextContinuationToken>
+  <Contents><Key>dir/a.txt</Key><LastModified>2019-10-12T17:50:30.000Z</LastModified><ETag>"etag-a"</ETag><Size>10</Size><StorageClass>STANDARD</StorageClass></Contents>
+</ListBucketResult>`))
+			return
+		}
+		w.Write([]byte(`<ListBucketResult>
+  <IsTruncated>false</IsTruncated>
+  <Contents><Key>dir/b.txt</Key><LastModified>2019-10-13T00:00:00.000Z</LastModified><ETag>"etag-b-2"</ETag><Size>20</Size></Contents>
+</ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	objects, err := newTestClient(server).ListAllObjects(context.Background(), "testbucket", "dir/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"encoding-type=url&list-type=2&prefix=dir%2F",
+		"continuation-token=token%2F1%3D&encoding-type=url&list-type=2&prefix=dir%2F",
+	}, queries)
+	assert.Equal(t, 2, len(objects))
+	assert.Equal(t, "dir/a.txt", objects[0].Key)
+	assert.Equal(t, int64(10), objects[0].Size)
+	assert.Equal(t, `"etag-a"`, objects[0].ETag)
+	assert.Equal(t, "STANDARD", objects[0].StorageClass)
+	assert.True(t, objects[0].LastModified.Equal(time.Date(2019, 10, 12, 17, 50, 30, 0, time.UTC)))
+	assert.Equal(t, "dir/b.txt", objects[1].Key)
+}
+
+func TestListObjectsV2(t *testing.T) {
+	var query string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`<ListBucketResult>
+  <Name>testbucket</Name><Prefix>dir%2F</Prefix><Delimiter>%2F</Delimiter><StartAfter>dir%2Fa</StartAfter>
+  <KeyCount>2</KeyCount><MaxKeys>2</MaxKeys><EncodingType>url</EncodingType><IsTruncated>false</IsTruncated>
+  <Contents><Key>dir%2Fhello+world%0A.txt</Key><Size>1</Size></Contents>
+  <CommonPrefixes><Prefix>dir%2Fsub%2B%2F</Prefix></CommonPrefixes>
+</ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	result, err := newTestClient(server).ListObjectsV2(context.Background(), ListObjectsV2Input{
+		Bucket:     "testbucket",
+		Prefix:     "dir/",
+		Delimiter:  "/",
+		StartAfter: "dir/a",
+		MaxKeys:    2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "delimiter=%2F&encoding-type=url&list-type=2&max-keys=2&prefix=dir%2F&start-after=dir%2Fa", query)
+	assert.Equal(t, "dir/", result.Prefix)
+	assert.Equal(t, "/", result.Delimiter)
+	assert.Equal(t, "dir/a", result.StartAfter)
+	assert.Equal(t, 2, result.MaxKeys)
+	assert.Equal(t, []Object{{Key: "dir/hello world\n.txt", Size: 1}}, result.Contents)
+	assert.Equal(t, []CommonPrefix{{Prefix: "dir/sub+/"}}, result.CommonPrefixes)
+}
+
+func TestObjectIterator(t *testing.T) {
+	cases := map[string]struct {
+		pages   []string
+		entries []string
+		err     bool
+	}{
+		"objects and prefixes in lexical order over pages": {
+			pages: []string{
+				`<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>1</NextContinuationToken>
+  <Contents><Key>a.txt</Key></Contents><Contents><Key>c.txt</Key></Contents>
+  <CommonPrefixes><Prefix>b/</Prefix></CommonPrefixes>
+</ListBucketResult>`,
+				`<ListBucketResult><IsTruncated>false</IsTruncated>
+  <CommonPrefixes><Prefix>d/</Prefix></CommonPrefixes>
+</ListBucketResult>`,
+			},
+			entries: []string{"a.txt", "b/ (prefix)", "c.txt", "d/ (prefix)"},
+		},
+		"empty page in the middle": {
+			pages: []string{
+				`<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>1</NextContinuationToken></ListBucketResult>`,
+				`<ListBucketResult><IsTruncated>false</IsTruncated><Contents><Key>a.txt</Key></Contents></ListBucketResult>`,
+			},
+			entries: []string{"a.txt"},
+		},
+		"truncated without token": {
+			pages: []string{
+				`<ListBucketResult><IsTruncated>true</IsTruncated><Contents><Key>a.txt</Key></Contents></ListBucketResult>`,
+			},
+			entries: []string{"a.txt"},
+			err:     true,
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			page := 0
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tc.pages[page]))
+				page++
+			}))
+			defer server.Close()
+
+			it := newTestClient(server).NewObjectIterator(context.Background(), ListObjectsV2Input{Bucket: "testbucket", Delimiter: "/"})
+			var entries []string
+			for it.Next() {
+				if prefix := it.Prefix(); prefix != "" {
+					entries = append(entries, prefix+" (prefix)")
+				} else {
+					entries = append(entries, it.Object().Key)
+				}
+			}
+			assert.Equal(t, tc.entries, entries)
+			assert.Equal(t, tc.err, it.Err() != nil)
+			assert.Equal(t, len(tc.pages), page)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	stdtime "time"
+
+	"github.com/hikaru7719/s3go/request"
+	"golang.org/x/xerrors"
+)
+
+// ObjectInfo is metadata of an object returned by HeadObject and GetObject.
+type ObjectInfo struct {
+	Key string
+	// Size is the length of the response body, which is the length of the range for a ranged GetObject.
+	Size         int64
+	ETag         string
+	LastModified stdtime.Time
+	ContentType  string
+	// Metadata is user-defined metadata given by x-amz-meta-* headers, keyed without the prefix.
+	Metadata map[string]string
+}
+
+func newObjectInfo(key string, res *http.Response) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        res.ContentLength,
+		ETag:        res.Header.Get("ETag"),
+		ContentType: res.Header.Get("Content-Type"),
+		Metadata:    make(map[string]string),
+	}
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	for name := range res.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-meta-") {
+			info.Metadata[strings.TrimPrefix(lower, "x-amz-meta-")] = res.Header.Get(name)
+		}
+	}
+	return info
+}
+
+// HeadObject is request to get metadata of the object of key in bucket.
+// S3 returns no body for HEAD, so a missing object is reported as *S3Error with NoSuchKey code.
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (*ObjectInfo, error) {
+	res, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest("HEAD", bucket, key, nil, nil, nil)
+	})
+	var s3Err *request.S3Error
+	if xerrors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound && s3Err.Code == http.StatusText(http.StatusNotFound) {
+		s3Err.Code = request.ErrCodeNoSuchKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return newObjectInfo(key, res), nil
+}
+
+// GetObjectInput is parameters of GetObject request.
+type GetObjectInput struct {
+	Bucket string
+	Key    string
+	// Start and End are the first and the last byte position of the range to get, when End is not 0.
+	Start int64
+	End   int64
+	// IfMatch makes the request fail with PreconditionFailed unless the ETag of the object is IfMatch.
+	IfMatch string
+}
+
+// GetObjectOutput is the response of GetObject. Body must be closed by the caller.
+type GetObjectOutput struct {
+	ObjectInfo
+	Body io.ReadCloser
+}
+
+// GetObject is request to get data of the object.
+func (c *Client) GetObject(ctx context.Context, input GetObjectInput) (*GetObjectOutput, error) {
+	header := make(map[string]string)
+	if input.End != 0 {
+		header["Range"] = "bytes=" + strconv.FormatInt(input.Start, 10) + "-" + strconv.FormatInt(input.End, 10)
+	}
+	if input.IfMatch != "" {
+		header["If-Match"] = input.IfMatch
+	}
+	res, err := c.do(ctx, func() (*http.Request, error) {
+		return c.newRequest("GET", input.Bucket, input.Key, nil, header, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GetObjectOutput{ObjectInfo: *newObjectInfo(input.Key, res), Body: res.Body}, nil
+}
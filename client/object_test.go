@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hikaru7719/s3go/request"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestHeadObject(t *testing.T) {
+	cases := map[string]struct {
+		testStatus  int
+		expectError string
+	}{
+		"found":     {testStatus: http.StatusOK},
+		"not found": {testStatus: http.StatusNotFound, expectError: request.ErrCodeNoSuchKey},
+		"forbidden": {testStatus: http.StatusForbidden, expectError: "Forbidden"},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "HEAD", r.Method)
+				w.Header().Set("Content-Length", "1024")
+				w.Header().Set("ETag", `"etag"`)
+				w.Header().Set("Last-Modified", "Sat, 12 Oct 2019 17:50:30 GMT")
+				w.Header().Set("Content-Type", "image/jpeg")
+				w.Header().Set("X-Amz-Meta-Author", "hikaru")
+				w.WriteHeader(tc.testStatus)
+			}))
+			defer server.Close()
+
+			info, err := newTestClient(server).HeadObject(context.Background(), "testbucket", "earth.jpg")
+			if tc.expectError != "" {
+				var s3Err *request.S3Error
+				assert.True(t, xerrors.As(err, &s3Err))
+				assert.Equal(t, tc.expectError, s3Err.Code)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "earth.jpg", info.Key)
+			assert.Equal(t, int64(1024), info.Size)
+			assert.Equal(t, `"etag"`, info.ETag)
+			assert.Equal(t, "image/jpeg", info.ContentType)
+			assert.Equal(t, 2019, info.LastModified.Year())
+			assert.Equal(t, map[string]string{"author": "hikaru"}, info.Metadata)
+		})
+	}
+}
+
+func TestGetObject(t *testing.T) {
+	var header http.Header
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		w.Write([]byte("cde"))
+	}))
+	defer server.Close()
+
+	out, err := newTestClient(server).GetObject(context.Background(), GetObjectInput{
+		Bucket:  "testbucket",
+		Key:     "testObject",
+		Start:   2,
+		End:     4,
+		IfMatch: `"etag"`,
+	})
+	assert.NoError(t, err)
+	defer out.Body.Close()
+	body, _ := ioutil.ReadAll(out.Body)
+	assert.Equal(t, "cde", string(body))
+	assert.Equal(t, int64(3), out.Size)
+	assert.Equal(t, "bytes=2-4", header.Get("Range"))
+	assert.Equal(t, `"etag"`, header.Get("If-Match"))
+}
@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // Default is package variable
 var Default = New()
@@ -10,7 +13,9 @@ func New() *Config {
 	acceessKeyID := os.Getenv("AWS_ACCESS_KEY_ID")
 	secretAccessKey := os.Getenv("AWS_ACCESS_KEY_SECRET")
 	region := os.Getenv("AWS_DEFAULT_REGION")
-	return &Config{AccessKeyID: acceessKeyID, SecretAccessKey: secretAccessKey, Region: region}
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+	pathStyle, _ := strconv.ParseBool(os.Getenv("AWS_S3_FORCE_PATH_STYLE"))
+	return &Config{AccessKeyID: acceessKeyID, SecretAccessKey: secretAccessKey, Region: region, Endpoint: endpoint, PathStyle: pathStyle}
 }
 
 // Config represents AWS settings
@@ -18,6 +23,8 @@ type Config struct {
 	AccessKeyID     string
 	SecretAccessKey string
 	Region          string
+	Endpoint        string
+	PathStyle       bool
 }
 
 // AWSAccessKeyID returns aws access key
@@ -34,3 +41,13 @@ func (c *Config) AWSSecretAccessKey() string {
 func (c *Config) AWSRegion() string {
 	return c.Region
 }
+
+// S3Endpoint returns URL of S3 compatible storage. It is empty for AWS S3.
+func (c *Config) S3Endpoint() string {
+	return c.Endpoint
+}
+
+// S3PathStyle returns whether bucket is addressed with path style
+func (c *Config) S3PathStyle() bool {
+	return c.PathStyle
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadProfile returns Config with the credentials and the region of profile
+// in the shared credentials file and the shared config file of AWS CLI.
+// The files are ~/.aws/credentials and ~/.aws/config unless AWS_SHARED_CREDENTIALS_FILE and AWS_CONFIG_FILE are set.
+// The endpoint settings are taken from environment variables like New.
+func LoadProfile(profile string) (*Config, error) {
+	home, _ := os.UserHomeDir()
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = filepath.Join(home, ".aws", "credentials")
+	}
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = filepath.Join(home, ".aws", "config")
+	}
+
+	credentials, err := readINI(credentialsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	settings, err := readINI(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	// The config file names sections "profile name" except for the default profile.
+	configSection := "profile " + profile
+	if profile == "default" {
+		configSection = profile
+	}
+	section, ok := credentials[profile]
+	if !ok {
+		section = settings[configSection]
+	}
+	if section["aws_access_key_id"] == "" {
+		return nil, fmt.Errorf("profile %s has no aws_access_key_id", profile)
+	}
+
+	c := New()
+	c.AccessKeyID = section["aws_access_key_id"]
+	c.SecretAccessKey = section["aws_secret_access_key"]
+	if region := settings[configSection]["region"]; region != "" {
+		c.Region = region
+	} else if region := section["region"]; region != "" {
+		c.Region = region
+	}
+	return c, nil
+}
+
+// readINI reads sections of key = value lines. Lines starting with # or ; are comments.
+func readINI(name string) (map[string]map[string]string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.Join(strings.Fields(line[1:len(line)-1]), " ")
+			section = make(map[string]string)
+			sections[name] = section
+		case section != nil:
+			if i := strings.Index(line, "="); i > 0 {
+				section[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	return sections, scanner.Err()
+}
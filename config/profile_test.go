@@ -0,0 +1,73 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	credentials := filepath.Join(dir, "credentials")
+	ioutil.WriteFile(credentials, []byte(`[default]
+aws_access_key_id = DEFAULTKEY
+aws_secret_access_key = defaultsecret
+
+# comment
+[work]
+aws_access_key_id=WORKKEY
+aws_secret_access_key=worksecret
+`), 0600)
+	config := filepath.Join(dir, "config")
+	ioutil.WriteFile(config, []byte(`[default]
+region = us-west-2
+
+[profile work]
+region = ap-northeast-1
+
+[profile sso]
+aws_access_key_id = SSOKEY
+aws_secret_access_key = ssosecret
+`), 0600)
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials)
+	os.Setenv("AWS_CONFIG_FILE", config)
+	defer os.Unsetenv("AWS_SHARED_CREDENTIALS_FILE")
+	defer os.Unsetenv("AWS_CONFIG_FILE")
+
+	cases := map[string]struct {
+		testProfile  string
+		expectKey    string
+		expectSecret string
+		expectRegion string
+		expectError  bool
+	}{
+		"default":        {testProfile: "default", expectKey: "DEFAULTKEY", expectSecret: "defaultsecret", expectRegion: "us-west-2"},
+		"named":          {testProfile: "work", expectKey: "WORKKEY", expectSecret: "worksecret", expectRegion: "ap-northeast-1"},
+		"in config file": {testProfile: "sso", expectKey: "SSOKEY", expectSecret: "ssosecret"},
+		"missing":        {testProfile: "missing", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			c, err := LoadProfile(tc.testProfile)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectKey, c.AWSAccessKeyID())
+			assert.Equal(t, tc.expectSecret, c.AWSSecretAccessKey())
+			if tc.expectRegion != "" {
+				assert.Equal(t, tc.expectRegion, c.AWSRegion())
+			}
+		})
+	}
+}
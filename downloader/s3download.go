@@ -0,0 +1,304 @@
+// Package downloader downloads an object from S3 to a file with parallel ranged GET requests.
+package downloader
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hikaru7719/s3go/client"
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/request"
+	"golang.org/x/xerrors"
+)
+
+const (
+	defaultConcurrency = 10
+	defaultPartSize    = 1024 * 1024 * 8
+)
+
+// Option configures S3Download
+type Option func(*S3Download)
+
+// WithConcurrency sets the number of parts downloaded at the same time.
+func WithConcurrency(n int) Option {
+	return func(d *S3Download) {
+		if n > 0 {
+			d.concurrency = n
+		}
+	}
+}
+
+// WithPartSize sets the size of the range downloaded by each request.
+func WithPartSize(size int64) Option {
+	return func(d *S3Download) {
+		if size > 0 {
+			d.partSize = size
+		}
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+// A part whose body fails while it is read is requested again from the byte it failed at.
+func WithRetryPolicy(policy request.RetryPolicy) Option {
+	return func(d *S3Download) {
+		d.retry = policy
+	}
+}
+
+// WithEndpoint sets the endpoint to send requests to, such as S3 compatible storage.
+func WithEndpoint(e *endpoint.Endpoint) Option {
+	return func(d *S3Download) {
+		d.endpoint = e
+	}
+}
+
+// WithHTTPClient sets http.Client to send requests with.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *S3Download) {
+		d.httpClient = c
+	}
+}
+
+// New returns S3Download downloading objectName in bucketName to fileName.
+func New(bucketName, objectName, fileName string, signature client.Signature, opts ...Option) *S3Download {
+	d := &S3Download{
+		endpoint:    endpoint.Default,
+		bucketName:  bucketName,
+		objectName:  objectName,
+		fileName:    fileName,
+		httpClient:  request.NewHTTPClient(),
+		concurrency: defaultConcurrency,
+		partSize:    defaultPartSize,
+		retry:       request.DefaultRetryPolicy(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.client = client.New(signature,
+		client.WithEndpoint(d.endpoint),
+		client.WithRetryPolicy(d.retry),
+		client.WithHTTPClient(d.httpClient))
+	return d
+}
+
+// S3Download is struct for downloading an object from AWS S3
+type S3Download struct {
+	endpoint    *endpoint.Endpoint
+	bucketName  string
+	objectName  string
+	fileName    string
+	httpClient  *http.Client
+	client      *client.Client
+	concurrency int
+	partSize    int64
+	retry       request.RetryPolicy
+	object      *client.ObjectInfo
+}
+
+// Run runs to download the object
+func (d *S3Download) Run() error {
+	return d.RunContext(context.Background())
+}
+
+// RunContext runs to download the object with ctx.
+// The object is written to a temporary file in the directory of fileName, which is renamed to fileName
+// only when all parts are downloaded. Parts are requested with If-Match, so that the download fails
+// instead of mixing data when the object is overwritten while it is downloaded.
+func (d *S3Download) RunContext(ctx context.Context) (err error) {
+	d.object, err = d.client.HeadObject(ctx, d.bucketName, d.objectName)
+	if err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(d.fileName), "."+filepath.Base(d.fileName)+".s3go-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+	if err = file.Truncate(d.object.Size); err != nil {
+		return err
+	}
+	if err = d.GetObjectContext(ctx, file); err != nil {
+		return err
+	}
+	if err = file.Chmod(0644); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), d.fileName)
+}
+
+// Size returns the size of the object. It is known after Run starts.
+func (d *S3Download) Size() int64 {
+	if d.object == nil {
+		return 0
+	}
+	return d.object.Size
+}
+
+// GetObject writes the object to w
+func (d *S3Download) GetObject(w io.WriterAt) error {
+	return d.GetObjectContext(context.Background(), w)
+}
+
+// GetObjectContext writes the object to w with ctx. The object must be headed before.
+// At most concurrency parts are downloaded at the same time.
+// When a part fails or ctx is canceled, the remaining parts are not started.
+func (d *S3Download) GetObjectContext(ctx context.Context, w io.WriterAt) error {
+	if d.object == nil {
+		return xerrors.New("object must be headed before getting it")
+	}
+	var wg sync.WaitGroup
+	queue := make(chan part)
+	errChan := make(chan error)
+	failed := make(chan struct{})
+	done := make(chan struct{})
+
+	var firstErr error
+	go func() {
+		for err := range errChan {
+			if err != nil && firstErr == nil {
+				firstErr = err
+				close(failed)
+			}
+		}
+		close(done)
+	}()
+
+	for i := 0; i < d.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				errChan <- d.getPart(ctx, p, w)
+			}
+		}()
+	}
+
+feed:
+	for _, p := range d.parts() {
+		select {
+		case queue <- p:
+		case <-failed:
+			break feed
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+
+	wg.Wait()
+	close(errChan)
+	<-done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// part is a byte range of the object from start to end inclusive.
+type part struct {
+	start int64
+	end   int64
+}
+
+func (d *S3Download) parts() []part {
+	size := d.object.Size
+	parts := make([]part, 0, (size+d.partSize-1)/d.partSize)
+	for start := int64(0); start < size; start += d.partSize {
+		end := start + d.partSize - 1
+		if end >= size {
+			end = size - 1
+		}
+		parts = append(parts, part{start: start, end: end})
+	}
+	return parts
+}
+
+// getPart downloads p and writes it to w at the same offset.
+// When reading the body fails, the rest of the part is requested again according to the retry policy.
+func (d *S3Download) getPart(ctx context.Context, p part, w io.WriterAt) error {
+	written := int64(0)
+	for attempt := 1; ; attempt++ {
+		n, err := d.getRange(ctx, p.start+written, p.end, w)
+		written += n
+		if err == nil {
+			return nil
+		}
+		// GetObject has already retried the request, so only a failure while reading the body is retried here.
+		var bodyErr *bodyError
+		if !xerrors.As(err, &bodyErr) || attempt >= d.retry.MaxAttempts || ctx.Err() != nil || !d.retry.Retryable(bodyErr.err) {
+			return xerrors.Errorf("error occurs when bytes %d-%d caused by : %w", p.start, p.end, err)
+		}
+		select {
+		case <-time.After(d.retry.Delay(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// getRange writes bytes from start to end inclusive to w and returns the number of bytes written.
+func (d *S3Download) getRange(ctx context.Context, start, end int64, w io.WriterAt) (int64, error) {
+	out, err := d.client.GetObject(ctx, client.GetObjectInput{
+		Bucket:  d.bucketName,
+		Key:     d.objectName,
+		Start:   start,
+		End:     end,
+		IfMatch: d.object.ETag,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer out.Body.Close()
+	length := end - start + 1
+	if out.Size >= 0 && out.Size != length {
+		// The endpoint ignored Range and returned the whole object.
+		return 0, xerrors.Errorf("response has %d bytes for range of %d bytes", out.Size, length)
+	}
+	n, err := io.Copy(&offsetWriter{w: w, offset: start}, io.LimitReader(out.Body, length))
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return n, &bodyError{err: err}
+	}
+	return n, nil
+}
+
+// bodyError is an error which occurs while a response body is read.
+type bodyError struct {
+	err error
+}
+
+func (e *bodyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *bodyError) Unwrap() error {
+	return e.err
+}
+
+// offsetWriter writes to w from offset, like io.SectionReader reads from an offset.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.offset)
+	o.offset += int64(n)
+	return n, err
+}
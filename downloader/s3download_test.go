@@ -0,0 +1,187 @@
+package downloader
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/request"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+type mockAuth struct{}
+
+func (m *mockAuth) Authorization(method, URL, payload string, header map[string]string) string {
+	return "testAuthorization"
+}
+
+// objectServer serves content with Range and If-Match support like S3.
+func objectServer(content []byte, etag string, handle func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handle != nil && handle(w, r) {
+			return
+		}
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func newTestDownload(t *testing.T, server *httptest.Server, opts ...Option) (*S3Download, string) {
+	dir, err := ioutil.TempDir("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _ := endpoint.Parse(server.URL, true)
+	opts = append([]Option{
+		WithEndpoint(e),
+		WithHTTPClient(server.Client()),
+		WithPartSize(4),
+		WithConcurrency(3),
+		WithRetryPolicy(request.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}),
+	}, opts...)
+	return New("testbucket", "dir/testObject", filepath.Join(dir, "local"), &mockAuth{}, opts...), dir
+}
+
+func TestRun(t *testing.T) {
+	cases := map[string]struct {
+		testContent string
+	}{
+		"multiple parts": {testContent: "abcdefghijklmnopq"},
+		"single part":    {testContent: "abc"},
+		"empty object":   {testContent: ""},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var mutex sync.Mutex
+			var ranges []string
+			server := objectServer([]byte(tc.testContent), `"etag"`, func(w http.ResponseWriter, r *http.Request) bool {
+				mutex.Lock()
+				defer mutex.Unlock()
+				if r.Method == "GET" {
+					ranges = append(ranges, r.Header.Get("Range"))
+					assert.Equal(t, `"etag"`, r.Header.Get("If-Match"))
+				}
+				return false
+			})
+			defer server.Close()
+
+			download, dir := newTestDownload(t, server)
+			defer os.RemoveAll(dir)
+			err := download.Run()
+			assert.NoError(t, err)
+			content, err := ioutil.ReadFile(filepath.Join(dir, "local"))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.testContent, string(content))
+			assert.Equal(t, (len(tc.testContent)+3)/4, len(ranges))
+			files, _ := ioutil.ReadDir(dir)
+			assert.Equal(t, 1, len(files), "temporary file is renamed")
+		})
+	}
+}
+
+func TestRunRetry(t *testing.T) {
+	content := "abcdefghijklmnopq"
+	var mutex sync.Mutex
+	failed := make(map[string]bool)
+	server := objectServer([]byte(content), `"etag"`, func(w http.ResponseWriter, r *http.Request) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.Method != "GET" || failed[r.Header.Get("Range")] {
+			return false
+		}
+		failed[r.Header.Get("Range")] = true
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	})
+	defer server.Close()
+
+	download, dir := newTestDownload(t, server)
+	defer os.RemoveAll(dir)
+	err := download.Run()
+	assert.NoError(t, err)
+	actual, _ := ioutil.ReadFile(filepath.Join(dir, "local"))
+	assert.Equal(t, content, string(actual))
+}
+
+func TestRunRetryExhausted(t *testing.T) {
+	var mutex sync.Mutex
+	var gets int
+	server := objectServer([]byte("abc"), `"etag"`, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Method != "GET" {
+			return false
+		}
+		mutex.Lock()
+		gets++
+		mutex.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return true
+	})
+	defer server.Close()
+
+	download, dir := newTestDownload(t, server)
+	defer os.RemoveAll(dir)
+	err := download.Run()
+	var s3Err *request.S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, http.StatusServiceUnavailable, s3Err.StatusCode)
+	assert.Equal(t, 3, gets, "GetObject is not retried again by the download")
+}
+
+func TestRunObjectChanged(t *testing.T) {
+	var mutex sync.Mutex
+	var heads int
+	server := objectServer([]byte("abcdefghijklmnopq"), `"new"`, func(w http.ResponseWriter, r *http.Request) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.Method == "HEAD" {
+			heads++
+			w.Header().Set("ETag", `"old"`)
+			w.Header().Set("Content-Length", "17")
+			return true
+		}
+		if r.Header.Get("If-Match") != `"new"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			w.Write([]byte(`<Error><Code>PreconditionFailed</Code></Error>`))
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+
+	download, dir := newTestDownload(t, server)
+	defer os.RemoveAll(dir)
+	err := download.Run()
+	var s3Err *request.S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, "PreconditionFailed", s3Err.Code)
+	assert.Equal(t, 1, heads)
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 0, len(files), "temporary file is removed")
+}
+
+func TestRunNoSuchKey(t *testing.T) {
+	server := objectServer(nil, "", func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusNotFound)
+		return true
+	})
+	defer server.Close()
+
+	download, dir := newTestDownload(t, server)
+	defer os.RemoveAll(dir)
+	err := download.Run()
+	var s3Err *request.S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, request.ErrCodeNoSuchKey, s3Err.Code)
+	assert.True(t, strings.Contains(err.Error(), "404"))
+}
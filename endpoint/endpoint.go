@@ -0,0 +1,87 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/hikaru7719/s3go/signature"
+)
+
+// Default is the global endpoint of AWS S3
+var Default = &Endpoint{Scheme: "https", Host: "s3.amazonaws.com", Region: defaultRegion, options: &Options{}}
+
+// Endpoint represents where S3 API requests are sent.
+type Endpoint struct {
+	Scheme string
+	Host   string
+	// Port is omitted from URL when it is 0.
+	Port int
+	// PathStyle addresses bucket as host/bucket/key instead of bucket.host/key.
+	PathStyle bool
+	// Region is the region requests are signed for. It is empty when Endpoint is given by URL.
+	Region string
+
+	options *Options
+}
+
+// Parse creates Endpoint from URL like "http://localhost:9000".
+// When scheme is omitted, https is used.
+func Parse(rawurl string, pathStyle bool) (*Endpoint, error) {
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("endpoint scheme must be http or https: %s", rawurl)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("endpoint has no host: %s", rawurl)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("endpoint must not have path: %s", rawurl)
+	}
+	e := &Endpoint{Scheme: u.Scheme, Host: u.Hostname(), PathStyle: pathStyle}
+	if port := u.Port(); port != "" {
+		e.Port, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint port: %s", rawurl)
+		}
+	}
+	return e, nil
+}
+
+// BucketHost returns host of requests for bucket, which is also Host header to sign.
+func (e *Endpoint) BucketHost(bucket string) string {
+	host := e.Host
+	if !e.PathStyle && bucket != "" {
+		host = bucket + "." + host
+	}
+	if e.Port != 0 {
+		host = fmt.Sprintf("%s:%d", host, e.Port)
+	}
+	return host
+}
+
+// URL returns URL of key in bucket. query is appended as it is.
+// key is URI-encoded in the same way as the canonical request of the signature.
+// When key is empty, URL of the bucket is returned.
+func (e *Endpoint) URL(bucket, key, query string) string {
+	key = signature.URIEncode(key, false)
+	path := "/" + key
+	if e.PathStyle && bucket != "" {
+		path = "/" + bucket
+		if key != "" {
+			path += "/" + key
+		}
+	}
+	u := fmt.Sprintf("%s://%s%s", e.Scheme, e.BucketHost(bucket), path)
+	if query != "" {
+		u += "?" + query
+	}
+	return u
+}
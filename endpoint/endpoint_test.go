@@ -0,0 +1,106 @@
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]struct {
+		testURL        string
+		testPathStyle  bool
+		expectEndpoint *Endpoint
+		expectError    bool
+	}{
+		"http with port": {
+			testURL:        "http://localhost:9000",
+			testPathStyle:  true,
+			expectEndpoint: &Endpoint{Scheme: "http", Host: "localhost", Port: 9000, PathStyle: true},
+		},
+		"without scheme": {
+			testURL:        "storage.example.com",
+			expectEndpoint: &Endpoint{Scheme: "https", Host: "storage.example.com"},
+		},
+		"trailing slash": {
+			testURL:        "https://storage.example.com/",
+			expectEndpoint: &Endpoint{Scheme: "https", Host: "storage.example.com"},
+		},
+		"unsupported scheme": {
+			testURL:     "ftp://storage.example.com",
+			expectError: true,
+		},
+		"with path": {
+			testURL:     "https://storage.example.com/s3",
+			expectError: true,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual, err := Parse(tc.testURL, tc.testPathStyle)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectEndpoint, actual)
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	cases := map[string]struct {
+		testEndpoint *Endpoint
+		testBucket   string
+		testKey      string
+		testQuery    string
+		expectHost   string
+		expectURL    string
+	}{
+		"virtual hosted style": {
+			testEndpoint: Default,
+			testBucket:   "testbucket",
+			testKey:      "testObject",
+			testQuery:    "uploads",
+			expectHost:   "testbucket.s3.amazonaws.com",
+			expectURL:    "https://testbucket.s3.amazonaws.com/testObject?uploads",
+		},
+		"path style": {
+			testEndpoint: &Endpoint{Scheme: "http", Host: "localhost", Port: 9000, PathStyle: true},
+			testBucket:   "testbucket",
+			testKey:      "dir/testObject",
+			expectHost:   "localhost:9000",
+			expectURL:    "http://localhost:9000/testbucket/dir/testObject",
+		},
+		"path style bucket": {
+			testEndpoint: &Endpoint{Scheme: "http", Host: "localhost", PathStyle: true},
+			testBucket:   "testbucket",
+			testQuery:    "list-type=2",
+			expectHost:   "localhost",
+			expectURL:    "http://localhost/testbucket?list-type=2",
+		},
+		"key with reserved characters": {
+			testEndpoint: Default,
+			testBucket:   "testbucket",
+			testKey:      "my dir/photo (1)+写真.jpg",
+			expectHost:   "testbucket.s3.amazonaws.com",
+			expectURL:    "https://testbucket.s3.amazonaws.com/my%20dir/photo%20%281%29%2B%E5%86%99%E7%9C%9F.jpg",
+		},
+		"virtual hosted style bucket": {
+			testEndpoint: Default,
+			testBucket:   "testbucket",
+			expectHost:   "testbucket.s3.amazonaws.com",
+			expectURL:    "https://testbucket.s3.amazonaws.com/",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			assert.Equal(t, tc.expectHost, tc.testEndpoint.BucketHost(tc.testBucket))
+			assert.Equal(t, tc.expectURL, tc.testEndpoint.URL(tc.testBucket, tc.testKey, tc.testQuery))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package endpoint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options selects a variant of AWS S3 endpoint.
+type Options struct {
+	DualStack bool
+	FIPS      bool
+	PathStyle bool
+}
+
+// Partitions of AWS regions
+const (
+	PartitionAWS      = "aws"
+	PartitionAWSCN    = "aws-cn"
+	PartitionAWSUSGov = "aws-us-gov"
+)
+
+const defaultRegion = "us-east-1"
+
+// Partition returns the partition which region belongs to.
+func Partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return PartitionAWSCN
+	case strings.HasPrefix(region, "us-gov-"):
+		return PartitionAWSUSGov
+	default:
+		return PartitionAWS
+	}
+}
+
+func dnsSuffix(partition string) string {
+	if partition == PartitionAWSCN {
+		return "amazonaws.com.cn"
+	}
+	return "amazonaws.com"
+}
+
+// supportsFIPS reports whether S3 has FIPS endpoint in region.
+func supportsFIPS(region string) bool {
+	return strings.HasPrefix(region, "us-") || strings.HasPrefix(region, "ca-")
+}
+
+// Resolve returns Endpoint of AWS S3 in region. us-east-1 is used when region is empty.
+// The returned Endpoint can be switched to another region by ForRegion.
+func Resolve(region string, opts Options) (*Endpoint, error) {
+	if region == "" {
+		region = defaultRegion
+	}
+	partition := Partition(region)
+	if opts.FIPS && !supportsFIPS(region) {
+		return nil, fmt.Errorf("S3 has no FIPS endpoint in region %s", region)
+	}
+
+	service := "s3"
+	if opts.FIPS {
+		service = "s3-fips"
+	}
+	if opts.DualStack {
+		service += ".dualstack"
+	}
+	return &Endpoint{
+		Scheme:    "https",
+		Host:      fmt.Sprintf("%s.%s.%s", service, region, dnsSuffix(partition)),
+		PathStyle: opts.PathStyle,
+		Region:    region,
+		options:   &opts,
+	}, nil
+}
+
+// ForRegion returns the same variant of Endpoint in another region.
+// It fails for Endpoint given by URL, because its hosts in other regions are unknown.
+func (e *Endpoint) ForRegion(region string) (*Endpoint, error) {
+	if e.options == nil {
+		return nil, fmt.Errorf("endpoint %s is not resolved from region", e.Host)
+	}
+	return Resolve(region, *e.options)
+}
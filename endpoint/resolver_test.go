@@ -0,0 +1,96 @@
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	cases := map[string]struct {
+		testRegion  string
+		testOptions Options
+		expectHost  string
+		expectError bool
+	}{
+		"default region": {
+			testRegion: "",
+			expectHost: "s3.us-east-1.amazonaws.com",
+		},
+		"tokyo": {
+			testRegion: "ap-northeast-1",
+			expectHost: "s3.ap-northeast-1.amazonaws.com",
+		},
+		"dualstack": {
+			testRegion:  "eu-west-1",
+			testOptions: Options{DualStack: true},
+			expectHost:  "s3.dualstack.eu-west-1.amazonaws.com",
+		},
+		"fips": {
+			testRegion:  "us-west-2",
+			testOptions: Options{FIPS: true},
+			expectHost:  "s3-fips.us-west-2.amazonaws.com",
+		},
+		"fips dualstack": {
+			testRegion:  "us-east-2",
+			testOptions: Options{FIPS: true, DualStack: true},
+			expectHost:  "s3-fips.dualstack.us-east-2.amazonaws.com",
+		},
+		"fips unsupported": {
+			testRegion:  "ap-northeast-1",
+			testOptions: Options{FIPS: true},
+			expectError: true,
+		},
+		"china": {
+			testRegion: "cn-north-1",
+			expectHost: "s3.cn-north-1.amazonaws.com.cn",
+		},
+		"china dualstack": {
+			testRegion:  "cn-northwest-1",
+			testOptions: Options{DualStack: true},
+			expectHost:  "s3.dualstack.cn-northwest-1.amazonaws.com.cn",
+		},
+		"gov cloud fips": {
+			testRegion:  "us-gov-west-1",
+			testOptions: Options{FIPS: true},
+			expectHost:  "s3-fips.us-gov-west-1.amazonaws.com",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual, err := Resolve(tc.testRegion, tc.testOptions)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "https", actual.Scheme)
+			assert.Equal(t, tc.expectHost, actual.Host)
+		})
+	}
+}
+
+func TestPartition(t *testing.T) {
+	assert.Equal(t, PartitionAWS, Partition("ap-northeast-1"))
+	assert.Equal(t, PartitionAWSCN, Partition("cn-north-1"))
+	assert.Equal(t, PartitionAWSUSGov, Partition("us-gov-east-1"))
+}
+
+func TestForRegion(t *testing.T) {
+	e, _ := Resolve("us-east-1", Options{DualStack: true, PathStyle: true})
+	actual, err := e.ForRegion("ap-northeast-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3.dualstack.ap-northeast-1.amazonaws.com", actual.Host)
+	assert.Equal(t, "ap-northeast-1", actual.Region)
+	assert.True(t, actual.PathStyle)
+
+	actual, err = Default.ForRegion("eu-west-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3.eu-west-1.amazonaws.com", actual.Host)
+
+	custom, _ := Parse("http://localhost:9000", true)
+	_, err = custom.ForRegion("eu-west-1")
+	assert.Error(t, err)
+}
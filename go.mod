@@ -3,7 +3,6 @@ module github.com/hikaru7719/s3go
 go 1.12
 
 require (
-	github.com/hashicorp/go-multierror v1.0.0
 	github.com/stretchr/testify v1.3.0
 	github.com/urfave/cli v1.21.0
 	golang.org/x/xerrors v0.0.0-20190717185122-a985d3407aa7
@@ -0,0 +1,82 @@
+// Package request sends signed requests to S3 and handles retries and error responses
+// shared by uploads, downloads and bucket operations.
+package request
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Error codes returned by S3 which callers often branch on.
+const (
+	ErrCodeAccessDenied      = "AccessDenied"
+	ErrCodeNoSuchBucket      = "NoSuchBucket"
+	ErrCodeNoSuchKey         = "NoSuchKey"
+	ErrCodeNoSuchUpload      = "NoSuchUpload"
+	ErrCodeInvalidPart       = "InvalidPart"
+	ErrCodeInvalidPartOrder  = "InvalidPartOrder"
+	ErrCodeEntityTooSmall    = "EntityTooSmall"
+	ErrCodeSlowDown          = "SlowDown"
+	ErrCodePermanentRedirect = "PermanentRedirect"
+)
+
+// S3Error is an error response returned by S3.
+// Use xerrors.As (or errors.As) to get it from an error returned by Do.
+type S3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	RequestID string   `xml:"RequestId"`
+	HostID    string   `xml:"HostId"`
+	Resource  string   `xml:"Resource"`
+	// Region and Endpoint are given by redirect and AuthorizationHeaderMalformed errors.
+	Region     string `xml:"Region"`
+	Endpoint   string `xml:"Endpoint"`
+	StatusCode int    `xml:"-"`
+	// BucketRegion is x-amz-bucket-region header of the response.
+	BucketRegion string `xml:"-"`
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("s3 error: status %d, code: %s, message: %s, request id: %s", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// NewS3Error reads the error response body and closes it.
+func NewS3Error(res *http.Response) error {
+	defer res.Body.Close()
+	byteBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	s3Err := ParseS3Error(byteBody)
+	if s3Err == nil {
+		s3Err = &S3Error{Code: http.StatusText(res.StatusCode), Message: string(byteBody)}
+	}
+	s3Err.StatusCode = res.StatusCode
+	s3Err.BucketRegion = res.Header.Get("x-amz-bucket-region")
+	return s3Err
+}
+
+// RegionHint returns the region of the bucket if the error tells that the request was sent to a wrong region.
+func (e *S3Error) RegionHint() string {
+	switch e.StatusCode {
+	case http.StatusMovedPermanently, http.StatusTemporaryRedirect, http.StatusBadRequest:
+	default:
+		return ""
+	}
+	if e.BucketRegion != "" {
+		return e.BucketRegion
+	}
+	return e.Region
+}
+
+// ParseS3Error returns nil if body is not an Error XML document.
+func ParseS3Error(body []byte) *S3Error {
+	s3Err := &S3Error{}
+	if err := xml.Unmarshal(body, s3Err); err != nil {
+		return nil
+	}
+	return s3Err
+}
@@ -0,0 +1,72 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseS3Error(t *testing.T) {
+	cases := map[string]struct {
+		testBody    string
+		expectError *S3Error
+	}{
+		"error document": {
+			testBody: `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message><Resource>/testbucket</Resource><RequestId>4442587FB7D0A2F9</RequestId><HostId>testhost</HostId></Error>`,
+			expectError: &S3Error{Code: ErrCodeNoSuchBucket, Message: "The specified bucket does not exist", Resource: "/testbucket", RequestID: "4442587FB7D0A2F9", HostID: "testhost"},
+		},
+		"other document": {
+			testBody:    `<CompleteMultipartUploadResult><ETag>"etag"</ETag></CompleteMultipartUploadResult>`,
+			expectError: nil,
+		},
+		"empty body": {
+			testBody:    "",
+			expectError: nil,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual := ParseS3Error([]byte(tc.testBody))
+			if tc.expectError == nil {
+				assert.Nil(t, actual)
+				return
+			}
+			assert.Equal(t, tc.expectError.Code, actual.Code)
+			assert.Equal(t, tc.expectError.Message, actual.Message)
+			assert.Equal(t, tc.expectError.Resource, actual.Resource)
+			assert.Equal(t, tc.expectError.RequestID, actual.RequestID)
+			assert.Equal(t, tc.expectError.HostID, actual.HostID)
+		})
+	}
+}
+
+func TestRegionHint(t *testing.T) {
+	cases := map[string]struct {
+		testError    *S3Error
+		expectRegion string
+	}{
+		"permanent redirect with header": {
+			testError:    &S3Error{Code: ErrCodePermanentRedirect, StatusCode: http.StatusMovedPermanently, BucketRegion: "eu-west-1"},
+			expectRegion: "eu-west-1",
+		},
+		"authorization header malformed": {
+			testError:    &S3Error{Code: "AuthorizationHeaderMalformed", StatusCode: http.StatusBadRequest, Region: "ap-northeast-1"},
+			expectRegion: "ap-northeast-1",
+		},
+		"access denied": {
+			testError:    &S3Error{Code: ErrCodeAccessDenied, StatusCode: http.StatusForbidden, BucketRegion: "eu-west-1"},
+			expectRegion: "",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			assert.Equal(t, tc.expectRegion, tc.testError.RegionHint())
+		})
+	}
+}
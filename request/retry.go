@@ -0,0 +1,150 @@
+package request
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/signature"
+	"golang.org/x/xerrors"
+)
+
+// RetryPolicy decides whether a failed S3 request is sent again and how long to wait before it.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry. It doubles on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay time.Duration
+	// Jitter randomizes the wait between zero and the computed delay.
+	Jitter bool
+	// RetryableStatusCodes are HTTP status codes treated as transient.
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy returns RetryPolicy used when no policy is given.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          5,
+		BaseDelay:            100 * time.Millisecond,
+		MaxDelay:             20 * time.Second,
+		Jitter:               true,
+		RetryableStatusCodes: []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	}
+}
+
+func (p RetryPolicy) retryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Retryable reports whether a request failed with err should be sent again.
+func (p RetryPolicy) Retryable(err error) bool {
+	var s3Err *S3Error
+	if xerrors.As(err, &s3Err) {
+		return p.retryableStatus(s3Err.StatusCode)
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	var netErr net.Error
+	return xerrors.As(err, &netErr)
+}
+
+// Delay returns the wait before the retry-th retry.
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter && delay > 0 {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+	return delay
+}
+
+// NewHTTPClient returns http.Client which doesn't follow redirects,
+// because a redirected request must be signed again for its new host.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Do sends a request built by newRequest with ctx and retries it according to policy.
+// newRequest is called for every attempt, so each attempt is signed with a fresh x-amz-date.
+// When S3 tells that the bucket is in another region, followRegion is called with the region,
+// and the request is sent again once if it returns true. followRegion may be nil.
+// A response with an error status is returned as *S3Error.
+func Do(ctx context.Context, client *http.Client, policy RetryPolicy, newRequest func() (*http.Request, error), followRegion func(region string) bool) (*http.Response, error) {
+	redirected := false
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req.WithContext(ctx))
+		if err == nil && res.StatusCode >= http.StatusMultipleChoices {
+			err = NewS3Error(res)
+		}
+		if err == nil {
+			return res, nil
+		}
+		var s3Err *S3Error
+		if !redirected && followRegion != nil && xerrors.As(err, &s3Err) && followRegion(s3Err.RegionHint()) {
+			redirected = true
+			attempt--
+			continue
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.Retryable(err) {
+			return nil, err
+		}
+		select {
+		case <-time.After(policy.Delay(attempt)):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// Signature signs requests to S3.
+type Signature interface {
+	Authorization(method, URL, payload string, header map[string]string) string
+}
+
+// regionalSignature is implemented by Signature which can sign requests for another region.
+type regionalSignature interface {
+	ForRegion(region string) *signature.Signature
+}
+
+// FollowRegion returns e and sig switched to region and reports whether they were switched.
+// They are not switched when region is empty or already the region of e,
+// when sig can't sign for another region, or when e is a custom endpoint.
+func FollowRegion(e *endpoint.Endpoint, sig Signature, region string) (*endpoint.Endpoint, Signature, bool) {
+	if region == "" || region == e.Region {
+		return e, sig, false
+	}
+	regional, ok := sig.(regionalSignature)
+	if !ok {
+		return e, sig, false
+	}
+	switched, err := e.ForRegion(region)
+	if err != nil {
+		return e, sig, false
+	}
+	return switched, regional.ForRegion(region), true
+}
@@ -0,0 +1,82 @@
+package request
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	assert.Equal(t, 100*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Delay(2))
+	assert.Equal(t, 800*time.Millisecond, policy.Delay(4))
+	assert.Equal(t, time.Second, policy.Delay(10))
+
+	policy.Jitter = true
+	for i := 0; i < 100; i++ {
+		assert.True(t, policy.Delay(3) <= 400*time.Millisecond)
+	}
+}
+
+type mockAuth struct{}
+
+func (m *mockAuth) Authorization(method, URL, payload string, header map[string]string) string {
+	return "testAuthorization"
+}
+
+func TestFollowRegion(t *testing.T) {
+	cases := map[string]struct {
+		testEndpoint  *endpoint.Endpoint
+		testSignature Signature
+		testRegion    string
+		expectHost    string
+		expectSwitch  bool
+	}{
+		"another region": {
+			testEndpoint:  endpoint.Default,
+			testSignature: signature.New(),
+			testRegion:    "eu-west-1",
+			expectHost:    "testbucket.s3.eu-west-1.amazonaws.com",
+			expectSwitch:  true,
+		},
+		"same region": {
+			testEndpoint:  endpoint.Default,
+			testSignature: signature.New(),
+			testRegion:    endpoint.Default.Region,
+			expectHost:    endpoint.Default.BucketHost("testbucket"),
+		},
+		"empty region": {
+			testEndpoint:  endpoint.Default,
+			testSignature: signature.New(),
+			expectHost:    endpoint.Default.BucketHost("testbucket"),
+		},
+		"custom endpoint": {
+			testEndpoint:  &endpoint.Endpoint{Scheme: "http", Host: "localhost", PathStyle: true},
+			testSignature: signature.New(),
+			testRegion:    "eu-west-1",
+			expectHost:    "localhost",
+		},
+		"signature without region": {
+			testEndpoint:  endpoint.Default,
+			testSignature: &mockAuth{},
+			testRegion:    "eu-west-1",
+			expectHost:    endpoint.Default.BucketHost("testbucket"),
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			e, sig, ok := FollowRegion(tc.testEndpoint, tc.testSignature, tc.testRegion)
+			assert.Equal(t, tc.expectSwitch, ok)
+			assert.Equal(t, tc.expectHost, e.BucketHost("testbucket"))
+			if !tc.expectSwitch {
+				assert.Equal(t, tc.testSignature, sig)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package signature
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StreamingPayload is x-amz-content-sha256 of a request whose payload is sent with aws-chunked encoding
+// and signed chunk by chunk.
+const StreamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// DefaultChunkSize is the size of each chunk of aws-chunked payload. S3 requires at least 8KiB except the last chunk.
+const DefaultChunkSize = 64 * 1024
+
+// signatureLength is the length of a hex encoded signature.
+const signatureLength = 64
+
+// ChunkedLength returns Content-Length of size bytes of payload encoded by aws-chunked with chunkSize,
+// which is sent with x-amz-decoded-content-length of size.
+func ChunkedLength(size int64, chunkSize int) int64 {
+	chunk := int64(chunkSize)
+	full := size / chunk
+	length := full * chunkHeaderLength(chunk)
+	if rest := size % chunk; rest > 0 {
+		length += chunkHeaderLength(rest)
+	}
+	return length + chunkHeaderLength(0) + size
+}
+
+// chunkHeaderLength returns the length of a chunk of size excluding its data,
+// which is "<hex size>;chunk-signature=<signature>\r\n" and "\r\n" after the data.
+func chunkHeaderLength(size int64) int64 {
+	return int64(len(strconv.FormatInt(size, 16))+len(";chunk-signature=")+signatureLength) + 4
+}
+
+// NewChunkedReader returns reader encoding data read from r with aws-chunked.
+// Each chunk of chunkSize bytes is signed with the signature of the previous chunk,
+// starting from the signature in authorization of the request sent at requestTime.
+// The request must have x-amz-content-sha256 of StreamingPayload, Content-Encoding of aws-chunked,
+// x-amz-decoded-content-length and Content-Length of ChunkedLength.
+func (s *Signature) NewChunkedReader(r io.Reader, chunkSize int, requestTime, authorization string) io.Reader {
+	date := requestTime[:8]
+	return &chunkedReader{
+		reader:      r,
+		chunk:       make([]byte, chunkSize),
+		key:         signatureKey(s.config.AWSSecretAccessKey(), date, s.region(), "s3"),
+		requestTime: requestTime,
+		scope:       fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region()),
+		previous:    seedSignature(authorization),
+	}
+}
+
+// seedSignature returns the signature in authorization.
+func seedSignature(authorization string) string {
+	i := strings.LastIndex(authorization, "Signature=")
+	if i < 0 {
+		return ""
+	}
+	return authorization[i+len("Signature="):]
+}
+
+type chunkedReader struct {
+	reader      io.Reader
+	chunk       []byte
+	key         []byte
+	requestTime string
+	scope       string
+	previous    string
+	// buffer is the encoded chunk not read yet.
+	buffer bytes.Buffer
+	done   bool
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	for r.buffer.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.nextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	return r.buffer.Read(p)
+}
+
+// nextChunk reads and encodes the next chunk. The empty last chunk is encoded at the end of r.
+func (r *chunkedReader) nextChunk() error {
+	n, err := io.ReadFull(r.reader, r.chunk)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	data := r.chunk[:n]
+	r.previous = r.sign(data)
+	fmt.Fprintf(&r.buffer, "%x;chunk-signature=%s\r\n", n, r.previous)
+	r.buffer.Write(data)
+	r.buffer.WriteString("\r\n")
+	r.done = n == 0
+	return nil
+}
+
+func (r *chunkedReader) sign(data []byte) string {
+	strToSign := "AWS4-HMAC-SHA256-PAYLOAD\n" + r.requestTime + "\n" + r.scope + "\n" + r.previous + "\n" +
+		hashSHA256("") + "\n" + hashSHA256(string(data))
+	return fmt.Sprintf("%x", makeHMAC(r.key, []byte(strToSign)))
+}
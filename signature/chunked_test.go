@@ -0,0 +1,56 @@
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedLength(t *testing.T) {
+	cases := map[string]struct {
+		testSize      int64
+		testChunkSize int
+		expectLength  int64
+	}{
+		"example":         {testSize: 66560, testChunkSize: 65536, expectLength: 66824},
+		"multiple chunks": {testSize: 131072, testChunkSize: 65536, expectLength: 131072 + 2*(5+17+64+4) + (1 + 17 + 64 + 4)},
+		"empty":           {testSize: 0, testChunkSize: 65536, expectLength: 86},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			assert.Equal(t, tc.expectLength, ChunkedLength(tc.testSize, tc.testChunkSize))
+		})
+	}
+}
+
+// TestNewChunkedReader uses the example of PUT Object with aws-chunked payload in the S3 API reference.
+func TestNewChunkedReader(t *testing.T) {
+	sig := &Signature{timer: &presignTimer{}, config: &presignConfig{}}
+	header := map[string]string{
+		"Host":                         "s3.amazonaws.com",
+		"x-amz-date":                   "20130524T000000Z",
+		"x-amz-storage-class":          "REDUCED_REDUNDANCY",
+		"Content-Encoding":             "aws-chunked",
+		"x-amz-decoded-content-length": "66560",
+		"Content-Length":               "66824",
+		"x-amz-content-sha256":         StreamingPayload,
+	}
+	authorization := sig.Authorization("PUT", "https://s3.amazonaws.com/examplebucket/chunkObject.txt", "", header)
+	assert.Equal(t, "4f232c4386841ef735655705268965c44a0e4690baa4adea153f7db9fa80a0a9", seedSignature(authorization))
+
+	payload := bytes.Repeat([]byte("a"), 66560)
+	body, err := ioutil.ReadAll(sig.NewChunkedReader(bytes.NewReader(payload), 65536, "20130524T000000Z", authorization))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(body)), ChunkedLength(int64(len(payload)), 65536))
+	expect := "10000;chunk-signature=ad80c730a21e5b8d04586a2213dd63b9a0e99e0e2307b0ade35a65485a288648\r\n" +
+		strings.Repeat("a", 65536) + "\r\n" +
+		"400;chunk-signature=0055627c9e194cb4542bae2aa5492e3c1575bbb81b612b7d234b86a503ef5497\r\n" +
+		strings.Repeat("a", 1024) + "\r\n" +
+		"0;chunk-signature=b6c6ea8a5354eaf15b3cb7646744f4275b71ea724fed81ceb9323e279d449df9\r\n\r\n"
+	assert.Equal(t, expect, string(body))
+}
@@ -0,0 +1,104 @@
+package signature
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	stdtime "time"
+)
+
+// PostPolicy is conditions of a browser-based upload with HTTP POST form.
+// A condition is not added to the policy when its field is zero value.
+type PostPolicy struct {
+	Bucket string
+	// Expires is the time from now until the policy expires.
+	Expires stdtime.Duration
+	// Key is the exact key of the upload. KeyPrefix allows any key starting with it instead.
+	Key       string
+	KeyPrefix string
+	// ContentType is the exact Content-Type. ContentTypePrefix allows any type starting with it, like "image/".
+	ContentType       string
+	ContentTypePrefix string
+	// MinContentLength and MaxContentLength are the range of the file size, used when MaxContentLength is not 0.
+	MinContentLength int64
+	MaxContentLength int64
+	// Metadata is x-amz-meta-* fields the form must have, keyed by the name without x-amz-meta-.
+	Metadata map[string]string
+}
+
+type policyDocument struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// PresignPost signs policy and returns the form fields to post with the file to the bucket URL.
+// The fields have policy, x-amz-algorithm, x-amz-credential, x-amz-date and x-amz-signature,
+// and also key, Content-Type and x-amz-meta-* when they are exactly given in policy.
+func (s *Signature) PresignPost(policy PostPolicy) (map[string]string, error) {
+	if policy.Bucket == "" {
+		return nil, fmt.Errorf("post policy requires bucket")
+	}
+	if (policy.Key == "") == (policy.KeyPrefix == "") {
+		return nil, fmt.Errorf("post policy requires either key or key prefix")
+	}
+	if policy.Expires <= 0 {
+		return nil, fmt.Errorf("post policy expires must be positive: %s", policy.Expires)
+	}
+	if policy.MaxContentLength != 0 && (policy.MinContentLength < 0 || policy.MinContentLength > policy.MaxContentLength) {
+		return nil, fmt.Errorf("invalid content length range: %d-%d", policy.MinContentLength, policy.MaxContentLength)
+	}
+	now := s.timer.Now()
+	requestTime, err := stdtime.Parse("20060102T150405Z", now)
+	if err != nil {
+		return nil, err
+	}
+	date := now[:8]
+	fields := map[string]string{
+		"x-amz-algorithm":  "AWS4-HMAC-SHA256",
+		"x-amz-credential": fmt.Sprintf("%s/%s/%s/s3/aws4_request", s.config.AWSAccessKeyID(), date, s.region()),
+		"x-amz-date":       now,
+	}
+	conditions := []interface{}{map[string]string{"bucket": policy.Bucket}}
+	if policy.Key != "" {
+		fields["key"] = policy.Key
+		conditions = append(conditions, map[string]string{"key": policy.Key})
+	} else {
+		conditions = append(conditions, []string{"starts-with", "$key", policy.KeyPrefix})
+	}
+	if policy.ContentType != "" {
+		fields["Content-Type"] = policy.ContentType
+		conditions = append(conditions, map[string]string{"Content-Type": policy.ContentType})
+	} else if policy.ContentTypePrefix != "" {
+		conditions = append(conditions, []string{"starts-with", "$Content-Type", policy.ContentTypePrefix})
+	}
+	if policy.MaxContentLength != 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", policy.MinContentLength, policy.MaxContentLength})
+	}
+	names := make([]string, 0, len(policy.Metadata))
+	for name := range policy.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := "x-amz-meta-" + strings.ToLower(name)
+		fields[field] = policy.Metadata[name]
+		conditions = append(conditions, map[string]string{field: policy.Metadata[name]})
+	}
+	for _, name := range []string{"x-amz-algorithm", "x-amz-credential", "x-amz-date"} {
+		conditions = append(conditions, map[string]string{name: fields[name]})
+	}
+
+	document, err := json.Marshal(policyDocument{
+		Expiration: requestTime.Add(policy.Expires).UTC().Format("2006-01-02T15:04:05.000Z"),
+		Conditions: conditions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(document)
+	fields["policy"] = encoded
+	fields["x-amz-signature"] = signature(s.config.AWSSecretAccessKey(), date, s.region(), "s3", encoded)
+	return fields, nil
+}
@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	stdtime "time"
+)
+
+// UnsignedPayload is used instead of the hashed payload when the payload is not signed.
+const UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// MaxPresignExpires is the longest time for which a presigned URL can be valid.
+const MaxPresignExpires = 7 * 24 * stdtime.Hour
+
+// Presign returns URL with the signature in its query string, which can be requested with method
+// by anyone without credentials until expires passes.
+// Only Host header is signed and the payload is UNSIGNED-PAYLOAD, so a presigned PUT accepts any body.
+func (s *Signature) Presign(method, URL string, expires stdtime.Duration) (string, error) {
+	switch method {
+	case "GET", "PUT", "HEAD", "DELETE":
+	default:
+		return "", fmt.Errorf("method must be GET, PUT, HEAD or DELETE: %s", method)
+	}
+	if expires < stdtime.Second || expires > MaxPresignExpires {
+		return "", fmt.Errorf("expires must be between 1s and %s: %s", MaxPresignExpires, expires)
+	}
+	u, err := url.Parse(URL)
+	if err != nil {
+		return "", err
+	}
+	now := s.timer.Now()
+	date := now[:8]
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region())
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.config.AWSAccessKeyID()+"/"+credentialScope)
+	query.Set("X-Amz-Date", now)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/stdtime.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	u.RawQuery = canonicalQuery(query)
+
+	request := canonicalRequestWithHash(method, u.String(), UnsignedPayload, map[string]string{"host": u.Host})
+	strToSign := stringToSign(now, s.region(), hashSHA256(request))
+	sig := signature(s.config.AWSSecretAccessKey(), date, s.region(), "s3", strToSign)
+	base := strings.SplitN(URL, "?", 2)[0]
+	return base + "?" + u.RawQuery + "&X-Amz-Signature=" + sig, nil
+}
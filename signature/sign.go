@@ -73,23 +73,61 @@ func hashSHA256(payload string) string {
 	return strings.ToLower(hexed)
 }
 
-// We should not encode URL for S3 request.
-// I don't encode query for /ObjectName?uploads.
+// URIEncode encodes str as UriEncode of AWS Signature Version 4.
+// Every byte except unreserved characters (A-Z, a-z, 0-9, '-', '.', '_' and '~') is percent-encoded.
+// '/' is kept as it is unless encodeSlash is true, so that an object key can be encoded as URL path.
+func URIEncode(str string, encodeSlash bool) string {
+	var buffer bytes.Buffer
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			buffer.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			buffer.WriteByte(c)
+		default:
+			fmt.Fprintf(&buffer, "%%%02X", c)
+		}
+	}
+	return buffer.String()
+}
+
+func canonicalQuery(values url.Values) string {
+	pairs := make([]string, 0, len(values))
+	for key, vs := range values {
+		for _, v := range vs {
+			pairs = append(pairs, URIEncode(key, true)+"="+URIEncode(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// S3 path is encoded only once, and a query without value like ?uploads is encoded as "uploads=".
 func canonicalRequest(method, URL, payload string, header map[string]string) string {
+	return canonicalRequestWithHash(method, URL, hashSHA256(payload), header)
+}
+
+// canonicalRequestWithHash is canonicalRequest with the hashed payload, or UNSIGNED-PAYLOAD.
+func canonicalRequestWithHash(method, URL, payloadHash string, header map[string]string) string {
 	HTTPRequestMethod := fmt.Sprintf("%s\n", method)
 	u, _ := url.Parse(URL)
-	canonicalURL := fmt.Sprintf("%s\n", u.EscapedPath())
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	canonicalURL := fmt.Sprintf("%s\n", URIEncode(path, false))
 
 	v := u.Query()
-	canonicalQueryString := fmt.Sprintf("%s\n", v.Encode())
+	canonicalQueryString := fmt.Sprintf("%s\n", canonicalQuery(v))
 
 	nrm := normarizeHeader(header)
 	canonicalHeaders := fmt.Sprintf("%s\n", nrm)
 
 	sortKeySlice := sortMapKey(header)
 	signedHeaders := fmt.Sprintf("%s\n", linkSlice(sortKeySlice))
-	hash := hashSHA256(payload)
-	return HTTPRequestMethod + canonicalURL + canonicalQueryString + canonicalHeaders + signedHeaders + hash
+	return HTTPRequestMethod + canonicalURL + canonicalQueryString + canonicalHeaders + signedHeaders + payloadHash
 }
 
 func stringToSign(ISODate, AWSRegion, hash string) string {
@@ -144,20 +182,66 @@ func New() *Signature {
 	return &Signature{timer: time.Default, config: config.Default}
 }
 
+// NewFromConfig creates Signature with the credentials and the region of config
+func NewFromConfig(config AWSConfig) *Signature {
+	return &Signature{timer: time.Default, config: config}
+}
+
 // Signature is struct making AWS Signature for authorization header of AWS API call
 type Signature struct {
 	timer  Timer
 	config AWSConfig
+	// regionOverride is used instead of the region of config when it is not empty.
+	regionOverride string
+}
+
+// ForRegion returns Signature signing requests for region instead of the configured region.
+func (s *Signature) ForRegion(region string) *Signature {
+	return &Signature{timer: s.timer, config: s.config, regionOverride: region}
 }
 
-// Authorization calculate signature
+func (s *Signature) region() string {
+	if s.regionOverride != "" {
+		return s.regionOverride
+	}
+	return s.config.AWSRegion()
+}
+
+// Authorization calculate signature.
+// The request time is taken from x-amz-date header when it is given,
+// so that the signature always matches the header sent with the request.
+// The hashed payload is also taken from x-amz-content-sha256 header when it is given, so that payload is not hashed twice
+// and STREAMING-AWS4-HMAC-SHA256-PAYLOAD or UNSIGNED-PAYLOAD can be signed.
 func (s *Signature) Authorization(method, URL, payload string, header map[string]string) string {
-	request := canonicalRequest(method, URL, payload, header)
+	now := s.requestTime(header)
+	date := now[:8]
+	payloadHash := headerValue(header, "x-amz-content-sha256")
+	if payloadHash == "" {
+		payloadHash = hashSHA256(payload)
+	}
+	request := canonicalRequestWithHash(method, URL, payloadHash, header)
 	hashedRequest := hashSHA256(request)
-	strToSign := stringToSign(s.timer.Now(), s.config.AWSRegion(), hashedRequest)
-	sig := signature(s.config.AWSSecretAccessKey(), s.timer.Date(), s.config.AWSRegion(), "s3", strToSign)
+	strToSign := stringToSign(now, s.region(), hashedRequest)
+	sig := signature(s.config.AWSSecretAccessKey(), date, s.region(), "s3", strToSign)
 	sortKeySlice := sortMapKey(header)
 	signedHeaders := fmt.Sprintf("%s", linkSlice(sortKeySlice))
-	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", s.timer.Date(), s.config.AWSRegion())
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.region())
 	return authorization(s.config.AWSAccessKeyID(), credentialScope, signedHeaders, sig)
 }
+
+func (s *Signature) requestTime(header map[string]string) string {
+	if value := headerValue(header, "x-amz-date"); len(value) >= 8 {
+		return value
+	}
+	return s.timer.Now()
+}
+
+// headerValue returns the value of name in header, ignoring case of the key.
+func headerValue(header map[string]string, name string) string {
+	for key, value := range header {
+		if strings.ToLower(key) == name {
+			return value
+		}
+	}
+	return ""
+}
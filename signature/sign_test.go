@@ -151,6 +151,36 @@ host:iam.amazonaws.com
 x-amz-date:20150830T123600Z
 
 content-type;host;x-amz-date
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`,
+		},
+		"encoded object key and query": {
+			testMethod: "POST",
+			testURL:    "https://examplebucket.s3.amazonaws.com/photos/my%20photo%20%281%29%2Ajpg/%E5%86%99%E7%9C%9F.jpg?uploads",
+			testHeader: map[string]string{
+				"Host": "examplebucket.s3.amazonaws.com",
+			},
+			testPayload: "",
+			expectString: `POST
+/photos/my%20photo%20%281%29%2Ajpg/%E5%86%99%E7%9C%9F.jpg
+uploads=
+host:examplebucket.s3.amazonaws.com
+
+host
+e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`,
+		},
+		"query with space and slash": {
+			testMethod: "GET",
+			testURL:    "https://examplebucket.s3.amazonaws.com/?prefix=my%20dir%2F&list-type=2",
+			testHeader: map[string]string{
+				"Host": "examplebucket.s3.amazonaws.com",
+			},
+			testPayload: "",
+			expectString: `GET
+/
+list-type=2&prefix=my%20dir%2F
+host:examplebucket.s3.amazonaws.com
+
+host
 e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855`,
 		},
 	}
@@ -273,3 +303,68 @@ func TestGetAuthrization(t *testing.T) {
 		})
 	}
 }
+
+type mockTimer struct{}
+
+func (m *mockTimer) Now() string  { return "20150830T123600Z" }
+func (m *mockTimer) Date() string { return "20150830" }
+
+type mockConfig struct{}
+
+func (m *mockConfig) AWSAccessKeyID() string     { return "AKIDEXAMPLE" }
+func (m *mockConfig) AWSSecretAccessKey() string { return "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY" }
+func (m *mockConfig) AWSRegion() string          { return "us-east-1" }
+
+func TestAuthorization(t *testing.T) {
+	cases := map[string]struct {
+		testHeader  map[string]string
+		expectScope string
+	}{
+		"date from timer": {
+			testHeader:  map[string]string{"Host": "examplebucket.s3.amazonaws.com"},
+			expectScope: "Credential=AKIDEXAMPLE/20150830/us-east-1/s3/aws4_request",
+		},
+		"date from x-amz-date header": {
+			testHeader:  map[string]string{"Host": "examplebucket.s3.amazonaws.com", "X-Amz-Date": "20150831T000000Z"},
+			expectScope: "Credential=AKIDEXAMPLE/20150831/us-east-1/s3/aws4_request",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			sig := &Signature{timer: &mockTimer{}, config: &mockConfig{}}
+			actual := sig.Authorization("GET", "https://examplebucket.s3.amazonaws.com/test.txt", "", tc.testHeader)
+			assert.Contains(t, actual, tc.expectScope)
+		})
+	}
+}
+
+func TestForRegion(t *testing.T) {
+	sig := &Signature{timer: &mockTimer{}, config: &mockConfig{}}
+	header := map[string]string{"Host": "examplebucket.s3.eu-west-1.amazonaws.com"}
+	actual := sig.ForRegion("eu-west-1").Authorization("GET", "https://examplebucket.s3.eu-west-1.amazonaws.com/test.txt", "", header)
+	assert.Contains(t, actual, "Credential=AKIDEXAMPLE/20150830/eu-west-1/s3/aws4_request")
+	assert.Equal(t, "us-east-1", sig.region())
+}
+
+func TestURIEncode(t *testing.T) {
+	cases := map[string]struct {
+		testString      string
+		testEncodeSlash bool
+		expectString    string
+	}{
+		"unreserved":         {testString: "AZaz09-._~", expectString: "AZaz09-._~"},
+		"space and reserved": {testString: "a b+c!*'()", expectString: "a%20b%2Bc%21%2A%27%28%29"},
+		"keep slash":         {testString: "dir/file.txt", expectString: "dir/file.txt"},
+		"encode slash":       {testString: "dir/file.txt", testEncodeSlash: true, expectString: "dir%2Ffile.txt"},
+		"unicode":            {testString: "写真", expectString: "%E5%86%99%E7%9C%9F"},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			assert.Equal(t, tc.expectString, URIEncode(tc.testString, tc.testEncodeSlash))
+		})
+	}
+}
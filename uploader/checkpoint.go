@@ -0,0 +1,123 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Checkpoint is the state of a multipart upload saved to a local file to resume it later.
+type Checkpoint struct {
+	Bucket   string         `json:"bucket"`
+	Key      string         `json:"key"`
+	UploadID string         `json:"upload_id"`
+	PartSize int64          `json:"part_size"`
+	FileSize int64          `json:"file_size"`
+	ModTime  time.Time      `json:"mod_time"`
+	Parts    map[int]string `json:"parts"`
+	// ChecksumAlgorithm is the algorithm given by WithUnsignedPayload, which must not change on resume
+	// because CompleteMultipartUpload needs the same checksum of every part.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksum_algorithm,omitempty"`
+}
+
+// LoadCheckpoint reads Checkpoint from the file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	byteBody, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(byteBody, checkpoint); err != nil {
+		return nil, xerrors.Errorf("invalid checkpoint file %s: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// Save writes Checkpoint to the file.
+// The file is replaced atomically, so a crash never leaves a broken checkpoint.
+func (c *Checkpoint) Save(path string) error {
+	byteBody, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(byteBody); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (c *Checkpoint) validate(s *S3Upload) error {
+	switch {
+	case c.Bucket != s.bucketName || c.Key != s.objectName:
+		return xerrors.Errorf("checkpoint is for s3://%s/%s, not s3://%s/%s", c.Bucket, c.Key, s.bucketName, s.objectName)
+	case c.PartSize != s.partSize:
+		return xerrors.Errorf("checkpoint part size %d does not match %d", c.PartSize, s.partSize)
+	case c.FileSize != s.fileSize || !c.ModTime.Equal(s.modTime):
+		return xerrors.New("file has been modified since the checkpoint was saved")
+	case c.ChecksumAlgorithm != s.checksum:
+		return xerrors.Errorf("checkpoint checksum algorithm %q does not match %q", c.ChecksumAlgorithm, s.checksum)
+	case c.UploadID == "":
+		return xerrors.New("checkpoint has no upload id")
+	}
+	return nil
+}
+
+// saveCheckpoint must be called with s.mutex locked once parts are being uploaded.
+func (s *S3Upload) saveCheckpoint() error {
+	if s.checkpointPath == "" {
+		return nil
+	}
+	checkpoint := &Checkpoint{
+		Bucket:            s.bucketName,
+		Key:               s.objectName,
+		UploadID:          s.uploadID,
+		PartSize:          s.partSize,
+		FileSize:          s.fileSize,
+		ModTime:           s.modTime,
+		Parts:             s.etagMapper,
+		ChecksumAlgorithm: s.checksum,
+	}
+	return checkpoint.Save(s.checkpointPath)
+}
+
+// resumeUpload restores the upload from checkpoint and reconciles its parts with S3.
+// Parts listed by S3 are trusted over the checkpoint, and a part with unexpected size is uploaded again.
+func (s *S3Upload) resumeUpload(ctx context.Context, checkpoint *Checkpoint) error {
+	if err := checkpoint.validate(s); err != nil {
+		return err
+	}
+	s.uploadID = checkpoint.UploadID
+	parts, err := s.ListPartsContext(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to list parts of upload %s: %w", s.uploadID, err)
+	}
+	s.etagMapper = make(map[int]string, len(parts))
+	for _, part := range parts {
+		if part.PartNumber > s.partCount || part.Size != s.partSection(part.PartNumber).Size() {
+			continue
+		}
+		s.etagMapper[part.PartNumber] = part.ETag
+		switch s.checksum {
+		case ChecksumCRC32:
+			s.setPartChecksum(part.PartNumber, part.ChecksumCRC32)
+		case ChecksumCRC32C:
+			s.setPartChecksum(part.PartNumber, part.ChecksumCRC32C)
+		}
+	}
+	return s.saveCheckpoint()
+}
@@ -0,0 +1,99 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointSaveAndLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "s3go")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.json")
+
+	expect := &Checkpoint{
+		Bucket:   "testbucket",
+		Key:      "testObject",
+		UploadID: "testUploadID",
+		PartSize: minPartSize,
+		FileSize: 100,
+		ModTime:  time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC),
+		Parts:    map[int]string{1: "etag1", 2: "etag2"},
+	}
+	assert.NoError(t, expect.Save(path))
+	actual, err := LoadCheckpoint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expect.UploadID, actual.UploadID)
+	assert.Equal(t, expect.Parts, actual.Parts)
+	assert.True(t, expect.ModTime.Equal(actual.ModTime))
+}
+
+func TestRunResume(t *testing.T) {
+	var mutex sync.Mutex
+	uploaded := make([]string, 0, 3)
+	var completed bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			completed = r.URL.Query().Get("uploadId") == "testUploadID"
+		case "GET":
+			fmt.Fprintf(w, `<ListPartsResult><IsTruncated>false</IsTruncated>
+<Part><PartNumber>1</PartNumber><ETag>"etag1"</ETag><Size>%d</Size></Part>
+<Part><PartNumber>2</PartNumber><ETag>"broken"</ETag><Size>1</Size></Part>
+</ListPartsResult>`, minPartSize)
+		case "PUT":
+			mutex.Lock()
+			uploaded = append(uploaded, r.URL.Query().Get("partNumber"))
+			mutex.Unlock()
+			w.Header().Set("ETag", "etag"+r.URL.Query().Get("partNumber"))
+		}
+	}))
+	defer server.Close()
+
+	upload := newTestUpload(t, server, minPartSize*2+1)
+	defer os.Remove(upload.file.Name())
+	dir, _ := ioutil.TempDir("", "s3go")
+	defer os.RemoveAll(dir)
+	upload.checkpointPath = filepath.Join(dir, "checkpoint.json")
+	upload.resume = true
+
+	upload.devideFile()
+	checkpoint := &Checkpoint{
+		Bucket:   upload.bucketName,
+		Key:      upload.objectName,
+		UploadID: "testUploadID",
+		PartSize: minPartSize,
+		FileSize: upload.fileSize,
+		ModTime:  upload.modTime,
+		Parts:    map[int]string{1: "etag1"},
+	}
+	checkpoint.Save(upload.checkpointPath)
+
+	err := upload.Run()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3"}, uploaded)
+	assert.True(t, completed)
+	_, err = os.Stat(upload.checkpointPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestResumeModifiedFile(t *testing.T) {
+	upload := &S3Upload{bucketName: "testbucket", objectName: "testObject", fileSize: 10, partSize: minPartSize}
+	checkpoint := &Checkpoint{Bucket: "testbucket", Key: "testObject", UploadID: "testUploadID", PartSize: minPartSize, FileSize: 20}
+	assert.Error(t, upload.resumeUpload(context.Background(), checkpoint))
+}
+
+func TestResumeChecksumMismatch(t *testing.T) {
+	upload := &S3Upload{bucketName: "testbucket", objectName: "testObject", fileSize: 10, partSize: minPartSize, checksum: ChecksumCRC32C}
+	checkpoint := &Checkpoint{Bucket: "testbucket", Key: "testObject", UploadID: "testUploadID", PartSize: minPartSize, FileSize: 10, ChecksumAlgorithm: ChecksumCRC32}
+	assert.Error(t, upload.resumeUpload(context.Background(), checkpoint))
+}
@@ -0,0 +1,102 @@
+package uploader
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/hikaru7719/s3go/signature"
+	"golang.org/x/xerrors"
+)
+
+// ChecksumAlgorithm is the checksum sent with each body for integrity when the payload is not signed.
+type ChecksumAlgorithm string
+
+// Checksum algorithms for WithUnsignedPayload.
+// CRC32 and CRC32C are flexible checksums, which S3 also verifies for the whole multipart upload.
+const (
+	ChecksumMD5    ChecksumAlgorithm = "MD5"
+	ChecksumCRC32  ChecksumAlgorithm = "CRC32"
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// WithUnsignedPayload sends bodies with x-amz-content-sha256 of UNSIGNED-PAYLOAD, so that they are not hashed with SHA-256,
+// and with the checksum of algorithm instead, which is MD5 when algorithm is empty.
+// The payload is signed as usual over http, since TLS is what protects unsigned bodies from being modified.
+// It is ignored by copy, and can't be used with WithStreamingSignature.
+func WithUnsignedPayload(algorithm ChecksumAlgorithm) Option {
+	return func(s *S3Upload) {
+		if algorithm == "" {
+			algorithm = ChecksumMD5
+		}
+		s.checksum = algorithm
+	}
+}
+
+// unsignedPayload reports whether bodies are sent with UNSIGNED-PAYLOAD.
+func (s *S3Upload) unsignedPayload() bool {
+	return s.checksum != "" && s.endpoint.Scheme == "https"
+}
+
+// payloadHash returns x-amz-content-sha256 of body.
+func (s *S3Upload) payloadHash(body []byte) string {
+	if s.unsignedPayload() {
+		return signature.UnsignedPayload
+	}
+	return hashSHA256(string(body))
+}
+
+// flexible reports whether algorithm must be declared when the multipart upload is initiated
+// and listed for each part in CompleteMultipartUpload.
+func (a ChecksumAlgorithm) flexible() bool {
+	return a == ChecksumCRC32 || a == ChecksumCRC32C
+}
+
+// checksumHeader returns the header name and the value of the checksum of body.
+func (a ChecksumAlgorithm) checksumHeader(body []byte) (string, string, error) {
+	switch a {
+	case ChecksumMD5:
+		sum := md5.Sum(body)
+		return "Content-MD5", base64.StdEncoding.EncodeToString(sum[:]), nil
+	case ChecksumCRC32:
+		return "x-amz-checksum-crc32", encodeCRC(crc32.ChecksumIEEE(body)), nil
+	case ChecksumCRC32C:
+		return "x-amz-checksum-crc32c", encodeCRC(crc32.Checksum(body, crc32cTable)), nil
+	default:
+		return "", "", xerrors.Errorf("unsupported checksum algorithm: %s", a)
+	}
+}
+
+// encodeCRC encodes sum as base64 of big-endian bytes.
+func encodeCRC(sum uint32) string {
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, sum)
+	return base64.StdEncoding.EncodeToString(value)
+}
+
+// setPartChecksum saves the flexible checksum of partNumber for CompleteMultipartUpload.
+func (s *S3Upload) setPartChecksum(partNumber int, value string) {
+	if !s.checksum.flexible() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.checksums == nil {
+		s.checksums = make(map[int]string)
+	}
+	s.checksums[partNumber] = value
+}
+
+// partChecksum returns the checksum of part for CompleteMultipartUpload.
+func (s *S3Upload) partChecksum(part Part) Part {
+	switch s.checksum {
+	case ChecksumCRC32:
+		part.ChecksumCRC32 = s.checksums[part.PartNumber]
+	case ChecksumCRC32C:
+		part.ChecksumCRC32C = s.checksums[part.PartNumber]
+	}
+	return part
+}
@@ -0,0 +1,136 @@
+package uploader
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksumHeader(t *testing.T) {
+	cases := map[string]struct {
+		testAlgorithm ChecksumAlgorithm
+		expectName    string
+		expectValue   string
+		expectError   bool
+	}{
+		"md5":     {testAlgorithm: ChecksumMD5, expectName: "Content-MD5", expectValue: "XUFAKrxLKna5cZ2REBfFkg=="},
+		"crc32":   {testAlgorithm: ChecksumCRC32, expectName: "x-amz-checksum-crc32", expectValue: "NhCmhg=="},
+		"crc32c":  {testAlgorithm: ChecksumCRC32C, expectName: "x-amz-checksum-crc32c", expectValue: "mnG7TA=="},
+		"unknown": {testAlgorithm: "SHA512", expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			name, value, err := tc.testAlgorithm.checksumHeader([]byte("hello"))
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectName, name)
+			assert.Equal(t, tc.expectValue, value)
+		})
+	}
+}
+
+func TestRunUnsignedPayload(t *testing.T) {
+	cases := map[string]struct {
+		testTLS         bool
+		testAlgorithm   ChecksumAlgorithm
+		expectUnsigned  bool
+		expectAlgorithm string
+		expectHeader    string
+	}{
+		"md5 over https": {
+			testTLS:        true,
+			testAlgorithm:  ChecksumMD5,
+			expectUnsigned: true,
+			expectHeader:   "Content-MD5",
+		},
+		"crc32c over https": {
+			testTLS:         true,
+			testAlgorithm:   ChecksumCRC32C,
+			expectUnsigned:  true,
+			expectAlgorithm: "CRC32C",
+			expectHeader:    "x-amz-checksum-crc32c",
+		},
+		"signed over http": {
+			testTLS:         false,
+			testAlgorithm:   ChecksumCRC32,
+			expectUnsigned:  false,
+			expectAlgorithm: "CRC32",
+			expectHeader:    "x-amz-checksum-crc32",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var mutex sync.Mutex
+			var algorithm string
+			var complete CompleteMultipartUpload
+			checksums := make(map[string]string)
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == "POST" && r.URL.Query().Get("uploadId") == "":
+					algorithm = r.Header.Get("x-amz-checksum-algorithm")
+					w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+				case r.Method == "POST":
+					body, _ := ioutil.ReadAll(r.Body)
+					assert.NoError(t, xml.Unmarshal(body, &complete))
+				case r.Method == "PUT":
+					body, _ := ioutil.ReadAll(r.Body)
+					expectHash := hashSHA256(string(body))
+					if tc.expectUnsigned {
+						expectHash = signature.UnsignedPayload
+					}
+					assert.Equal(t, expectHash, r.Header.Get("x-amz-content-sha256"))
+					_, value, _ := tc.testAlgorithm.checksumHeader(body)
+					assert.Equal(t, value, r.Header.Get(tc.expectHeader))
+					partNumber := r.URL.Query().Get("partNumber")
+					mutex.Lock()
+					checksums[partNumber] = value
+					mutex.Unlock()
+					w.Header().Set("ETag", `"etag`+partNumber+`"`)
+				}
+			})
+			server := httptest.NewUnstartedServer(handler)
+			if tc.testTLS {
+				server.StartTLS()
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			upload := newTestUpload(t, server, minPartSize+1)
+			defer os.Remove(upload.file.Name())
+			upload.multipartThreshold = minPartSize
+			upload.retry = DefaultRetryPolicy()
+			WithUnsignedPayload(tc.testAlgorithm)(upload)
+
+			assert.NoError(t, upload.Run())
+			assert.Equal(t, tc.expectAlgorithm, algorithm)
+			assert.Equal(t, 2, len(checksums))
+			assert.Equal(t, 2, len(complete.Part))
+			for _, part := range complete.Part {
+				expect := Part{XMLName: part.XMLName, PartNumber: part.PartNumber, ETag: part.ETag}
+				switch tc.testAlgorithm {
+				case ChecksumCRC32:
+					expect.ChecksumCRC32 = checksums[strconv.Itoa(part.PartNumber)]
+				case ChecksumCRC32C:
+					expect.ChecksumCRC32C = checksums[strconv.Itoa(part.PartNumber)]
+				}
+				assert.Equal(t, expect, part)
+			}
+		})
+	}
+}
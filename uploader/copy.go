@@ -0,0 +1,155 @@
+package uploader
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hikaru7719/s3go/request"
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/hikaru7719/s3go/time"
+	"golang.org/x/xerrors"
+)
+
+// CopySource is the object copied by S3Upload created with NewCopy.
+type CopySource struct {
+	Bucket string
+	Key    string
+	// Size decides whether the object is copied with a single CopyObject request or UploadPartCopy.
+	Size int64
+	// ETag is sent as x-amz-copy-source-if-match when it is not empty,
+	// so that the copy fails if the object is replaced while its parts are copied.
+	ETag string
+	// ContentType and Metadata are set on the copy made with UploadPartCopy,
+	// which doesn't copy them from the source unlike CopyObject.
+	ContentType string
+	// Metadata is user-defined metadata sent as x-amz-meta-* headers, keyed without the prefix.
+	Metadata map[string]string
+}
+
+// NewCopy returns S3Upload copying source to objectName in bucketName on the server side.
+// An object up to 5GiB is copied with CopyObject, and a larger one with UploadPartCopy of byte ranges.
+// Metadata of the source is copied with the object.
+func NewCopy(bucketName, objectName string, source CopySource, signature Signature, opts ...Option) *S3Upload {
+	s := newS3Upload(bucketName, objectName, signature, opts)
+	s.copySource = &source
+	s.fileSize = source.Size
+	return s
+}
+
+func (s *S3Upload) runCopy(ctx context.Context) (err error) {
+	if s.checkpointPath != "" {
+		return xerrors.New("checkpoint is not supported for copy")
+	}
+	// Copied parts have no body to checksum.
+	s.checksum = ""
+	if s.fileSize <= maxPartSize {
+		s.progress.begin(s.fileSize, 1, 0, 0)
+		return s.CopyObjectContext(ctx)
+	}
+	s.partSize, err = choosePartSize(s.fileSize, s.partSize)
+	if err != nil {
+		return err
+	}
+	s.partCount = int((s.fileSize + s.partSize - 1) / s.partSize)
+	if err := s.InitialMultipartUploadContext(ctx); err != nil {
+		return err
+	}
+	s.progress.begin(s.fileSize, s.partCount, 0, 0)
+	defer s.abortIfFailed(&err)
+	if err := s.PutObjectContext(ctx); err != nil {
+		return err
+	}
+	return s.CompleteUploadObjectContext(ctx)
+}
+
+// CopyObject is request to copy the whole source object with a single request.
+func (s *S3Upload) CopyObject() error {
+	return s.CopyObjectContext(context.Background())
+}
+
+// CopyObjectContext is CopyObject with ctx
+func (s *S3Upload) CopyObjectContext(ctx context.Context) error {
+	if err := s.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	_, err := s.copy(ctx, func() (*http.Request, error) {
+		return s.newCopyRequest(s.objectURL(""), "")
+	})
+	if err != nil {
+		return err
+	}
+	s.progress.copied(1, s.fileSize)
+	s.progress.completePart()
+	return nil
+}
+
+// copyPart copies the byte range of partNumber from the source with UploadPartCopy and returns ETag of the part.
+func (s *S3Upload) copyPart(ctx context.Context, partNumber int) (string, error) {
+	offset, size := s.partRange(partNumber)
+	etag, err := s.copy(ctx, func() (*http.Request, error) {
+		url := s.uploadURL(fmt.Sprintf("partNumber=%d", partNumber))
+		return s.newCopyRequest(url, fmt.Sprintf("bytes=%d-%d", offset, offset+size-1))
+	})
+	if err != nil {
+		return "", err
+	}
+	s.progress.copied(partNumber, size)
+	return etag, nil
+}
+
+// copyResult is response XML of CopyObject and UploadPartCopy requests.
+type copyResult struct {
+	ETag string
+}
+
+// copy sends the request of newRequest and returns ETag in the response body.
+// S3 may report an error with 200 OK while it copies the object, so the body is checked for an error first.
+func (s *S3Upload) copy(ctx context.Context, newRequest func() (*http.Request, error)) (string, error) {
+	res, err := s.do(ctx, newRequest)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	byteBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	if s3Err := request.ParseS3Error(byteBody); s3Err != nil {
+		s3Err.StatusCode = res.StatusCode
+		return "", s3Err
+	}
+	result := copyResult{}
+	if err := xml.Unmarshal(byteBody, &result); err != nil {
+		return "", xerrors.Errorf("invalid copy response: %w", err)
+	}
+	if result.ETag == "" {
+		return "", xerrors.New("copy response has no ETag")
+	}
+	return result.ETag, nil
+}
+
+// newCopyRequest returns PUT request to url copying the source. The whole object is copied when byteRange is empty.
+func (s *S3Upload) newCopyRequest(url, byteRange string) (*http.Request, error) {
+	req, err := http.NewRequest("PUT", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("x-amz-date", time.Default.Now())
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", emptySHA256)
+	req.Header.Add("x-amz-copy-source", "/"+signature.URIEncode(s.copySource.Bucket+"/"+s.copySource.Key, false))
+	if byteRange != "" {
+		req.Header.Add("x-amz-copy-source-range", byteRange)
+	}
+	if s.copySource.ETag != "" {
+		req.Header.Add("x-amz-copy-source-if-match", s.copySource.ETag)
+	}
+	headerMap := s.convertToMap(req.Header)
+	authorization := s.signature.Authorization("PUT", url, "", headerMap)
+	req.Header.Add("Authorization", authorization)
+	return req, nil
+}
@@ -0,0 +1,130 @@
+package uploader
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestRunCopy(t *testing.T) {
+	cases := map[string]struct {
+		testSize        int64
+		testContentType string
+		testMetadata    map[string]string
+		testPartError   bool
+		expectRequests  []string
+		expectError     bool
+	}{
+		"copy object": {
+			testSize:       maxPartSize,
+			expectRequests: []string{"PUT /testbucket/testObject "},
+		},
+		"upload part copy": {
+			testSize: maxPartSize + 1,
+			expectRequests: []string{
+				"POST /testbucket/testObject?uploadId=testUploadID ",
+				"POST /testbucket/testObject?uploads ",
+				"PUT /testbucket/testObject?partNumber=1&uploadId=testUploadID bytes=0-5368709119",
+				"PUT /testbucket/testObject?partNumber=2&uploadId=testUploadID bytes=5368709120-5368709120",
+			},
+		},
+		"upload part copy with metadata": {
+			testSize:        maxPartSize + 1,
+			testContentType: "image/jpeg",
+			testMetadata:    map[string]string{"author": "hikaru"},
+			expectRequests: []string{
+				"POST /testbucket/testObject?uploadId=testUploadID ",
+				"POST /testbucket/testObject?uploads ",
+				"PUT /testbucket/testObject?partNumber=1&uploadId=testUploadID bytes=0-5368709119",
+				"PUT /testbucket/testObject?partNumber=2&uploadId=testUploadID bytes=5368709120-5368709120",
+			},
+		},
+		"error in 200 OK response": {
+			testSize:      maxPartSize + 1,
+			testPartError: true,
+			expectError:   true,
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var mutex sync.Mutex
+			requests := make([]string, 0, 4)
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("x-amz-copy-source-range"))
+				mutex.Unlock()
+				switch {
+				case r.Method == "POST" && r.URL.Query().Get("uploadId") == "":
+					assert.Equal(t, tc.testContentType, r.Header.Get("Content-Type"))
+					assert.Equal(t, tc.testMetadata["author"], r.Header.Get("x-amz-meta-author"))
+					w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+				case r.Method == "PUT":
+					assert.Equal(t, "/srcbucket/dir/a%20b.txt", r.Header.Get("x-amz-copy-source"))
+					assert.Equal(t, `"srcetag"`, r.Header.Get("x-amz-copy-source-if-match"))
+					if tc.testPartError {
+						w.Write([]byte(`<Error><Code>InternalError</Code></Error>`))
+						return
+					}
+					fmt.Fprintf(w, `<CopyPartResult><ETag>"etag-%s"</ETag></CopyPartResult>`, r.URL.Query().Get("partNumber"))
+				case r.Method == "DELETE":
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+
+			source := CopySource{
+				Bucket:      "srcbucket",
+				Key:         "dir/a b.txt",
+				Size:        tc.testSize,
+				ETag:        `"srcetag"`,
+				ContentType: tc.testContentType,
+				Metadata:    tc.testMetadata,
+			}
+			retry := DefaultRetryPolicy()
+			retry.MaxAttempts = 1
+			upload := NewCopy("testbucket", "testObject", source, &mockAuth{},
+				WithEndpoint(testEndpoint(server)), WithPartSize(maxPartSize), WithRetryPolicy(retry))
+			upload.client = server.Client()
+
+			err := upload.Run()
+			if tc.expectError {
+				var s3Err *S3Error
+				assert.True(t, xerrors.As(err, &s3Err))
+				assert.Equal(t, "InternalError", s3Err.Code)
+				assert.Contains(t, requests, "DELETE /testbucket/testObject?uploadId=testUploadID ")
+				return
+			}
+			sort.Strings(requests)
+			assert.Equal(t, tc.expectRequests, requests)
+			assert.NoError(t, err)
+			if tc.testSize > maxPartSize {
+				assert.Equal(t, map[int]string{1: `"etag-1"`, 2: `"etag-2"`}, upload.etagMapper)
+			}
+		})
+	}
+}
+
+func TestCopyObjectProgress(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`))
+	}))
+	defer server.Close()
+
+	var last Progress
+	source := CopySource{Bucket: "srcbucket", Key: "srcObject", Size: 1024}
+	upload := NewCopy("testbucket", "testObject", source, &mockAuth{},
+		WithEndpoint(testEndpoint(server)), WithProgressListener(ProgressFunc(func(p Progress) { last = p })))
+	upload.client = server.Client()
+
+	err := upload.CopyObject()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), last.SentBytes)
+}
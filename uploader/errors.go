@@ -0,0 +1,20 @@
+package uploader
+
+import "github.com/hikaru7719/s3go/request"
+
+// Error codes returned by S3 which callers often branch on.
+const (
+	ErrCodeAccessDenied      = request.ErrCodeAccessDenied
+	ErrCodeNoSuchBucket      = request.ErrCodeNoSuchBucket
+	ErrCodeNoSuchKey         = request.ErrCodeNoSuchKey
+	ErrCodeNoSuchUpload      = request.ErrCodeNoSuchUpload
+	ErrCodeInvalidPart       = request.ErrCodeInvalidPart
+	ErrCodeInvalidPartOrder  = request.ErrCodeInvalidPartOrder
+	ErrCodeEntityTooSmall    = request.ErrCodeEntityTooSmall
+	ErrCodeSlowDown          = request.ErrCodeSlowDown
+	ErrCodePermanentRedirect = request.ErrCodePermanentRedirect
+)
+
+// S3Error is an error response returned by S3.
+// Use xerrors.As (or errors.As) to get it from an error returned by S3Upload.
+type S3Error = request.S3Error
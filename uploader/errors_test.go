@@ -0,0 +1,81 @@
+package uploader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestInitialMultipartUploadError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:   testEndpoint(server),
+		objectName: "testObject",
+		signature:  &mockAuth{},
+		client:     server.Client(),
+	}
+	err := upload.InitialMultipartUpload()
+	var s3Err *S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, ErrCodeAccessDenied, s3Err.Code)
+	assert.Equal(t, http.StatusForbidden, s3Err.StatusCode)
+	assert.Equal(t, "", upload.uploadID)
+}
+
+func TestPutObjectError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`<Error><Code>NoSuchUpload</Code></Error>`))
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:    testEndpoint(server),
+		objectName:  "testObject",
+		signature:   &mockAuth{},
+		etagMapper:  make(map[int]string),
+		mutex:       new(sync.Mutex),
+		client:      server.Client(),
+		concurrency: 1,
+	}
+	upload.file = tempFile(t, nil)
+	defer os.Remove(upload.file.Name())
+	upload.file.Truncate(minPartSize*2 + 1)
+	upload.devideFile()
+
+	err := upload.PutObject()
+	var s3Err *S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, ErrCodeNoSuchUpload, s3Err.Code)
+	assert.Equal(t, 0, len(upload.etagMapper))
+}
+
+func TestCompleteUploadObjectError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<Error><Code>InvalidPart</Code></Error>`))
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:   testEndpoint(server),
+		objectName: "testObject",
+		signature:  &mockAuth{},
+		etagMapper: map[int]string{1: "etag"},
+		client:     server.Client(),
+	}
+	err := upload.CompleteUploadObject()
+	var s3Err *S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, ErrCodeInvalidPart, s3Err.Code)
+	assert.Equal(t, http.StatusOK, s3Err.StatusCode)
+}
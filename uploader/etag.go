@@ -0,0 +1,54 @@
+package uploader
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// PartSize returns the size of each part S3Upload uses for a file of fileSize when WithPartSize(partSize) is given.
+func PartSize(fileSize, partSize int64) (int64, error) {
+	return choosePartSize(fileSize, partSize)
+}
+
+// ETag calculates the quoted ETag S3 returns for an object of size bytes read from r.
+// When parts is 0, it is the ETag of a single PUT, which is MD5 of the object.
+// Otherwise it is the ETag of a multipart upload divided into parts of partSize,
+// which is MD5 of the concatenated MD5 of each part followed by the number of parts.
+func ETag(r io.ReaderAt, size int64, parts int, partSize int64) (string, error) {
+	if parts == 0 {
+		sum, err := md5Sum(io.NewSectionReader(r, 0, size))
+		if err != nil {
+			return "", err
+		}
+		return `"` + hex.EncodeToString(sum) + `"`, nil
+	}
+	if partSize <= 0 || (size+partSize-1)/partSize != int64(parts) {
+		return "", xerrors.Errorf("size %d can't be divided into %d parts of %d bytes", size, parts, partSize)
+	}
+	sums := make([]byte, 0, md5.Size*parts)
+	for offset := int64(0); offset < size; offset += partSize {
+		length := partSize
+		if size-offset < length {
+			length = size - offset
+		}
+		sum, err := md5Sum(io.NewSectionReader(r, offset, length))
+		if err != nil {
+			return "", err
+		}
+		sums = append(sums, sum...)
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), parts), nil
+}
+
+func md5Sum(r io.Reader) ([]byte, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,44 @@
+package uploader
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETag(t *testing.T) {
+	content := strings.Repeat("a", 10)
+	sum := func(s string) []byte {
+		hash := md5.Sum([]byte(s))
+		return hash[:]
+	}
+	partSums := md5.Sum(append(append(sum("aaaa"), sum("aaaa")...), sum("aa")...))
+
+	cases := map[string]struct {
+		testParts    int
+		testPartSize int64
+		expectETag   string
+		expectError  bool
+	}{
+		"single":          {expectETag: `"` + hex.EncodeToString(sum(content)) + `"`},
+		"multipart":       {testParts: 3, testPartSize: 4, expectETag: fmt.Sprintf(`"%s-3"`, hex.EncodeToString(partSums[:]))},
+		"wrong part size": {testParts: 2, testPartSize: 4, expectError: true},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			etag, err := ETag(strings.NewReader(content), int64(len(content)), tc.testParts, tc.testPartSize)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectETag, etag)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package uploader
+
+import "context"
+
+// PartLimiter limits the number of requests sent at the same time by S3Uploads sharing it.
+// It caps the total of parts in flight when many files are uploaded concurrently.
+type PartLimiter struct {
+	tokens chan struct{}
+}
+
+// NewPartLimiter returns PartLimiter allowing n requests at the same time.
+func NewPartLimiter(n int) *PartLimiter {
+	if n < 1 {
+		n = 1
+	}
+	return &PartLimiter{tokens: make(chan struct{}, n)}
+}
+
+// WithPartLimiter shares limiter with other S3Uploads.
+// Each S3Upload still uploads at most concurrency parts at the same time.
+func WithPartLimiter(limiter *PartLimiter) Option {
+	return func(s *S3Upload) {
+		s.limiter = limiter
+	}
+}
+
+// acquire blocks until a request can be sent or ctx is canceled.
+// All methods do nothing on nil PartLimiter.
+func (l *PartLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.tokens <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *PartLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.tokens
+}
@@ -0,0 +1,58 @@
+package uploader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartLimiter(t *testing.T) {
+	var mutex sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			if r.URL.Query().Get("uploadId") == "" {
+				w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+			}
+		case "PUT":
+			mutex.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mutex.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mutex.Lock()
+			inFlight--
+			mutex.Unlock()
+			w.Header().Set("ETag", r.URL.Query().Get("partNumber"))
+		}
+	}))
+	defer server.Close()
+
+	limiter := NewPartLimiter(3)
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		upload := newTestUpload(t, server, minPartSize*3)
+		defer os.Remove(upload.file.Name())
+		WithPartLimiter(limiter)(upload)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = upload.Run()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.True(t, maxInFlight <= 3)
+}
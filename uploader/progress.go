@@ -0,0 +1,164 @@
+package uploader
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Progress is the state of an upload reported to ProgressListener.
+// TotalBytes and TotalParts are -1 while uploading data of unknown length.
+type Progress struct {
+	TotalBytes     int64
+	SentBytes      int64
+	TotalParts     int
+	CompletedParts int
+	// Throughput is bytes per second sent since the upload started.
+	Throughput float64
+	// ETA is the estimated time until all bytes are sent. It is zero until throughput is known.
+	ETA time.Duration
+}
+
+// ProgressListener receives Progress while part bodies are read to be sent.
+// OnProgress is never called concurrently.
+type ProgressListener interface {
+	OnProgress(Progress)
+}
+
+// ProgressFunc is an adapter to use a function as ProgressListener.
+type ProgressFunc func(Progress)
+
+// OnProgress calls f(p).
+func (f ProgressFunc) OnProgress(p Progress) {
+	f(p)
+}
+
+// WithProgressListener sets the listener notified of the upload progress.
+func WithProgressListener(listener ProgressListener) Option {
+	return func(s *S3Upload) {
+		s.progress = &progressTracker{listener: listener}
+	}
+}
+
+// progressTracker counts bytes per part, so that a part sent again by retry is not counted twice.
+// All methods do nothing on nil progressTracker.
+type progressTracker struct {
+	mutex          sync.Mutex
+	listener       ProgressListener
+	start          time.Time
+	totalBytes     int64
+	sentBytes      int64
+	resumedBytes   int64
+	totalParts     int
+	completedParts int
+	partBytes      map[int]int64
+}
+
+func (t *progressTracker) begin(totalBytes int64, totalParts, completedParts int, completedBytes int64) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.start = time.Now()
+	t.totalBytes = totalBytes
+	t.sentBytes = completedBytes
+	t.resumedBytes = completedBytes
+	t.totalParts = totalParts
+	t.completedParts = completedParts
+	t.partBytes = make(map[int]int64)
+	t.report()
+}
+
+// reader wraps the body of partNumber. Bytes counted for the previous attempt of the part are discarded.
+func (t *progressTracker) reader(partNumber int, r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	t.resetPart(partNumber)
+	return &progressReader{reader: r, partNumber: partNumber, tracker: t}
+}
+
+func (t *progressTracker) resetPart(partNumber int) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.sentBytes -= t.partBytes[partNumber]
+	delete(t.partBytes, partNumber)
+	t.report()
+}
+
+func (t *progressTracker) add(partNumber int, n int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	// partBytes is nil when a part is sent without begin, e.g. by CopyObject called directly.
+	if t.partBytes == nil {
+		t.partBytes = make(map[int]int64)
+	}
+	t.partBytes[partNumber] += n
+	t.sentBytes += n
+	t.report()
+}
+
+// copied counts n bytes of partNumber copied on the server side, which are never read by reader.
+func (t *progressTracker) copied(partNumber int, n int64) {
+	if t == nil {
+		return
+	}
+	t.add(partNumber, n)
+}
+
+// setTotal sets the size of data which was unknown when the upload began.
+func (t *progressTracker) setTotal(totalBytes int64, totalParts int) {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.totalBytes = totalBytes
+	t.totalParts = totalParts
+	t.report()
+}
+
+func (t *progressTracker) completePart() {
+	if t == nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.completedParts++
+	t.report()
+}
+
+// report must be called with t.mutex locked.
+func (t *progressTracker) report() {
+	p := Progress{
+		TotalBytes:     t.totalBytes,
+		SentBytes:      t.sentBytes,
+		TotalParts:     t.totalParts,
+		CompletedParts: t.completedParts,
+	}
+	if elapsed := time.Since(t.start).Seconds(); elapsed > 0 {
+		p.Throughput = float64(t.sentBytes-t.resumedBytes) / elapsed
+	}
+	if p.Throughput > 0 && t.totalBytes >= 0 {
+		p.ETA = time.Duration(float64(t.totalBytes-t.sentBytes) / p.Throughput * float64(time.Second))
+	}
+	t.listener.OnProgress(p)
+}
+
+type progressReader struct {
+	reader     io.Reader
+	partNumber int
+	tracker    *progressTracker
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.tracker.add(r.partNumber, int64(n))
+	}
+	return n, err
+}
@@ -0,0 +1,57 @@
+package uploader
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunProgress(t *testing.T) {
+	var mutex sync.Mutex
+	failed := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT":
+			ioutil.ReadAll(r.Body)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if r.URL.Query().Get("partNumber") == "2" && !failed {
+				failed = true
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("ETag", "etag")
+		}
+	}))
+	defer server.Close()
+
+	upload := newTestUpload(t, server, minPartSize*2+10)
+	defer os.Remove(upload.file.Name())
+	upload.retry = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}
+	var last Progress
+	var maxSent int64
+	WithProgressListener(ProgressFunc(func(p Progress) {
+		last = p
+		if p.SentBytes > maxSent {
+			maxSent = p.SentBytes
+		}
+	}))(upload)
+
+	err := upload.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(minPartSize*2+10), last.TotalBytes)
+	assert.Equal(t, last.TotalBytes, last.SentBytes)
+	assert.Equal(t, last.TotalBytes, maxSent)
+	assert.Equal(t, 3, last.TotalParts)
+	assert.Equal(t, 3, last.CompletedParts)
+	assert.True(t, last.Throughput > 0)
+	assert.Equal(t, time.Duration(0), last.ETA)
+}
@@ -0,0 +1,93 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// defaultReaderPartSize is part size for data of unknown length.
+// It allows uploading about 156GiB with maxParts parts.
+const defaultReaderPartSize = 1024 * 1024 * 16
+
+// runReader uploads data read from s.reader.
+func (s *S3Upload) runReader(ctx context.Context) (err error) {
+	if s.checkpointPath != "" {
+		return xerrors.New("checkpoint is not supported for uploading from io.Reader")
+	}
+	if s.partSize == 0 {
+		s.partSize = defaultReaderPartSize
+	}
+	if s.partSize < minPartSize || s.partSize > maxPartSize {
+		return xerrors.Errorf("part size %d must be between %d and %d", s.partSize, minPartSize, int64(maxPartSize))
+	}
+
+	body, eof, err := s.readPart()
+	if err != nil {
+		return err
+	}
+	if eof {
+		s.progress.begin(int64(len(body)), 1, 0, 0)
+		return s.putSingle(ctx, bodySection(body))
+	}
+
+	err = s.InitialMultipartUploadContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.abortIfFailed(&err)
+
+	s.progress.begin(-1, -1, 0, 0)
+	partNumber := 0
+	var total int64
+	err = s.uploadParts(ctx, func() (filePart, bool, error) {
+		if body == nil {
+			if eof {
+				return filePart{}, false, nil
+			}
+			var readErr error
+			body, eof, readErr = s.readPart()
+			if readErr != nil {
+				return filePart{}, false, readErr
+			}
+			if len(body) == 0 {
+				s.progress.setTotal(total, partNumber)
+				return filePart{}, false, nil
+			}
+		}
+		partNumber++
+		if partNumber > maxParts {
+			return filePart{}, false, xerrors.Errorf("data is larger than %d parts of %d bytes", maxParts, s.partSize)
+		}
+		total += int64(len(body))
+		if eof {
+			s.progress.setTotal(total, partNumber)
+		}
+		part := filePart{number: partNumber, section: bodySection(body)}
+		body = nil
+		return part, true, nil
+	})
+	if err != nil {
+		return err
+	}
+	return s.CompleteUploadObjectContext(ctx)
+}
+
+// readPart reads one part from s.reader. eof is true when the part is the last one.
+func (s *S3Upload) readPart() (body []byte, eof bool, err error) {
+	buffer := make([]byte, s.partSize)
+	n, err := io.ReadFull(s.reader, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return buffer[:n], true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return buffer, false, nil
+}
+
+func bodySection(body []byte) *io.SectionReader {
+	return io.NewSectionReader(bytes.NewReader(body), 0, int64(len(body)))
+}
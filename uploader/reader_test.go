@@ -0,0 +1,92 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReader(t *testing.T) {
+	cases := map[string]struct {
+		testSize      int
+		expectParts   int
+		expectRequest []string
+	}{
+		"smaller than one part": {
+			testSize:      1024,
+			expectParts:   0,
+			expectRequest: []string{"PUT"},
+		},
+		"empty": {
+			testSize:      0,
+			expectParts:   0,
+			expectRequest: []string{"PUT"},
+		},
+		"multiple parts": {
+			testSize:      minPartSize*2 + 100,
+			expectParts:   3,
+			expectRequest: []string{"POST", "PUT", "PUT", "PUT", "POST"},
+		},
+		"exactly two parts": {
+			testSize:      minPartSize * 2,
+			expectParts:   2,
+			expectRequest: []string{"POST", "PUT", "PUT", "POST"},
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			data := make([]byte, tc.testSize)
+			for i := range data {
+				data[i] = byte(i % 251)
+			}
+			var mutex sync.Mutex
+			methods := make([]string, 0, 5)
+			parts := make(map[int][]byte)
+			var single []byte
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				mutex.Lock()
+				defer mutex.Unlock()
+				methods = append(methods, r.Method)
+				switch {
+				case r.Method == "POST" && r.URL.Query().Get("uploadId") == "":
+					w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+				case r.Method == "PUT" && r.URL.Query().Get("partNumber") != "":
+					partNumber, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+					parts[partNumber] = body
+					w.Header().Set("ETag", "etag")
+				case r.Method == "PUT":
+					single = body
+				}
+			}))
+			defer server.Close()
+
+			reader := struct{ io.Reader }{bytes.NewReader(data)}
+			upload := NewFromReader("testbucket", "testObject", reader, &mockAuth{}, WithEndpoint(testEndpoint(server)), WithPartSize(minPartSize))
+			upload.client = server.Client()
+
+			err := upload.Run()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectRequest, methods)
+			assert.Equal(t, tc.expectParts, len(parts))
+			if tc.expectParts == 0 {
+				assert.Equal(t, data, append([]byte{}, single...))
+				return
+			}
+			uploaded := make([]byte, 0, tc.testSize)
+			for i := 1; i <= tc.expectParts; i++ {
+				uploaded = append(uploaded, parts[i]...)
+			}
+			assert.Equal(t, data, uploaded)
+		})
+	}
+}
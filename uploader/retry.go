@@ -0,0 +1,22 @@
+package uploader
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hikaru7719/s3go/request"
+)
+
+// RetryPolicy decides whether a failed S3 request is sent again and how long to wait before it.
+type RetryPolicy = request.RetryPolicy
+
+// DefaultRetryPolicy returns RetryPolicy used when no policy is given.
+func DefaultRetryPolicy() RetryPolicy {
+	return request.DefaultRetryPolicy()
+}
+
+// do sends a request built by newRequest with ctx and retries it according to the retry policy.
+// When S3 tells that the bucket is in another region, the request is sent again to that region once.
+func (s *S3Upload) do(ctx context.Context, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	return request.Do(ctx, s.client, s.retry, newRequest, s.followRegion)
+}
@@ -0,0 +1,70 @@
+package uploader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestDoRetry(t *testing.T) {
+	var attempts int
+	var dates []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		dates = append(dates, r.Header.Get("x-amz-date"))
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>retried</UploadId></InitiateMultipartUploadResult>`))
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:   testEndpoint(server),
+		objectName: "testObject",
+		signature:  &mockAuth{},
+		client:     server.Client(),
+		retry: RetryPolicy{
+			MaxAttempts:          3,
+			BaseDelay:            time.Millisecond,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+		},
+	}
+	err := upload.InitialMultipartUpload()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, 3, len(dates))
+	assert.Equal(t, "retried", upload.uploadID)
+}
+
+func TestDoRetryGiveUp(t *testing.T) {
+	var attempts int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:   testEndpoint(server),
+		objectName: "testObject",
+		signature:  &mockAuth{},
+		client:     server.Client(),
+		retry: RetryPolicy{
+			MaxAttempts:          2,
+			BaseDelay:            time.Millisecond,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+		},
+	}
+	_, err := upload.do(context.Background(), upload.newInitialRequest)
+	var s3Err *S3Error
+	assert.True(t, xerrors.As(err, &s3Err))
+	assert.Equal(t, http.StatusServiceUnavailable, s3Err.StatusCode)
+	assert.Equal(t, 2, attempts)
+}
@@ -2,6 +2,7 @@ package uploader
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/xml"
@@ -15,36 +16,144 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	stdtime "time"
 
-	"github.com/hashicorp/go-multierror"
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/request"
+	"github.com/hikaru7719/s3go/signature"
 	"github.com/hikaru7719/s3go/time"
 	"golang.org/x/xerrors"
 )
 
-var (
-	baseHost = "s3.amazonaws.com"
+// Limits of multipart upload defined by S3.
+const (
+	minPartSize   = 1024 * 1024 * 5
+	maxPartSize   = 1024 * 1024 * 1024 * 5
+	maxParts      = 10000
+	maxObjectSize = 1024 * 1024 * 1024 * 1024 * 5
 )
 
+const (
+	defaultConcurrency = 10
+	defaultThreshold   = 1024 * 1024 * 8
+	emptySHA256        = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Option configures S3Upload
+type Option func(*S3Upload)
+
+// WithConcurrency sets the number of parts uploaded at the same time.
+func WithConcurrency(n int) Option {
+	return func(s *S3Upload) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *S3Upload) {
+		s.retry = policy
+	}
+}
+
+// WithAbortOnFailure sets whether Run aborts the multipart upload when it fails.
+// Disable it to keep uploaded parts for resuming the upload later.
+func WithAbortOnFailure(abort bool) Option {
+	return func(s *S3Upload) {
+		s.abortOnFailure = abort
+	}
+}
+
+// WithCheckpoint saves the state of the upload to the file after every part.
+// The multipart upload is kept on failure so that it can be resumed with WithResume.
+func WithCheckpoint(path string) Option {
+	return func(s *S3Upload) {
+		s.checkpointPath = path
+	}
+}
+
+// WithResume resumes the upload saved in the checkpoint file.
+// Only the parts missing on S3 are uploaded. If the checkpoint file doesn't exist, a new upload starts.
+func WithResume() Option {
+	return func(s *S3Upload) {
+		s.resume = true
+	}
+}
+
+// WithMultipartThreshold sets the file size from which multipart upload is used.
+// A smaller file is uploaded with a single PUT request.
+func WithMultipartThreshold(size int64) Option {
+	return func(s *S3Upload) {
+		s.multipartThreshold = size
+	}
+}
+
+// WithPartSize sets the size of each part of multipart upload.
+// When size is 0, the smallest part size valid for the file is chosen automatically.
+func WithPartSize(size int64) Option {
+	return func(s *S3Upload) {
+		s.partSize = size
+	}
+}
+
+// WithEndpoint sets the endpoint to send requests to, such as S3 compatible storage.
+func WithEndpoint(e *endpoint.Endpoint) Option {
+	return func(s *S3Upload) {
+		s.endpoint = e
+	}
+}
+
+// WithKey sets the object key to upload to, which is the base name of the file by default.
+func WithKey(key string) Option {
+	return func(s *S3Upload) {
+		s.objectName = key
+	}
+}
+
 // New returns S3Upload
-func New(bucketName, fileName string, signature Signature) (*S3Upload, error) {
-	host := fmt.Sprintf("%s.%s", bucketName, baseHost)
-	etagMapper := make(map[int]string, 20)
+func New(bucketName, fileName string, signature Signature, opts ...Option) (*S3Upload, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
 	}
 
 	objectName := filepath.Base(fileName)
+	s := newS3Upload(bucketName, objectName, signature, opts)
+	s.file = file
+	return s, nil
+}
+
+// NewFromReader returns S3Upload uploading data read from reader as objectName.
+// The length of data may be unknown, since it is read and buffered one part at a time.
+// Data smaller than one part is uploaded with a single PUT request.
+func NewFromReader(bucketName, objectName string, reader io.Reader, signature Signature, opts ...Option) *S3Upload {
+	s := newS3Upload(bucketName, objectName, signature, opts)
+	s.reader = reader
+	return s
+}
+
+func newS3Upload(bucketName, objectName string, signature Signature, opts []Option) *S3Upload {
+	etagMapper := make(map[int]string, 20)
 	mutex := new(sync.Mutex)
-	return &S3Upload{
-		host:       host,
-		bucketName: bucketName,
-		objectName: objectName,
-		signature:  signature,
-		etagMapper: etagMapper,
-		file:       file,
-		mutex:      mutex,
-	}, nil
+	s := &S3Upload{
+		endpoint:           endpoint.Default,
+		bucketName:         bucketName,
+		objectName:         objectName,
+		signature:          signature,
+		etagMapper:         etagMapper,
+		mutex:              mutex,
+		client:             request.NewHTTPClient(),
+		concurrency:        defaultConcurrency,
+		retry:              DefaultRetryPolicy(),
+		abortOnFailure:     true,
+		multipartThreshold: defaultThreshold,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Signature is interface
@@ -54,59 +163,152 @@ type Signature interface {
 
 // S3Upload is struct for upliading file to AWS S3
 type S3Upload struct {
-	host       string
-	bucketName string
-	objectName string
-	uploadID   string
-	signature  Signature
-	file       io.ReadCloser
-	etagMapper map[int]string
-	fileSlice  [][]byte
-	mutex      *sync.Mutex
+	endpoint           *endpoint.Endpoint
+	bucketName         string
+	objectName         string
+	uploadID           string
+	signature          Signature
+	file               *os.File
+	reader             io.Reader
+	fileSize           int64
+	partSize           int64
+	partCount          int
+	etagMapper         map[int]string
+	mutex              *sync.Mutex
+	client             *http.Client
+	concurrency        int
+	retry              RetryPolicy
+	abortOnFailure     bool
+	checkpointPath     string
+	resume             bool
+	modTime            stdtime.Time
+	multipartThreshold int64
+	progress           *progressTracker
+	limiter            *PartLimiter
+	copySource         *CopySource
+	streaming          bool
+	checksum           ChecksumAlgorithm
+	// checksums are flexible checksums of parts keyed by part number.
+	checksums map[int]string
 }
 
 // Run runs to upload file
 func (s *S3Upload) Run() error {
-	err := s.InitialMultipartUpload()
+	return s.RunContext(context.Background())
+}
+
+// RunContext runs to upload file with ctx.
+// A file smaller than multipartThreshold is uploaded with a single PUT request.
+// If a step fails or ctx is canceled after the multipart upload is initiated,
+// the upload is aborted unless abortOnFailure is disabled.
+func (s *S3Upload) RunContext(ctx context.Context) (err error) {
+	if s.streaming && s.checksum != "" && s.copySource == nil {
+		return xerrors.New("unsigned payload can't be used with streaming signature")
+	}
+	if s.reader != nil {
+		return s.runReader(ctx)
+	}
+	if s.copySource != nil {
+		return s.runCopy(ctx)
+	}
+	defer s.file.Close()
+	err = s.devideFile()
 	if err != nil {
 		return err
 	}
-	err = s.devideFile()
+	// An empty file has no part to upload, so it is always put with a single request.
+	if s.fileSize == 0 || s.fileSize < s.multipartThreshold && s.fileSize <= maxPartSize {
+		s.progress.begin(s.fileSize, 1, 0, 0)
+		return s.PutSingleObjectContext(ctx)
+	}
+	err = s.startUpload(ctx)
 	if err != nil {
 		return err
 	}
-	err = s.PutObject()
+	var completedBytes int64
+	for partNumber := range s.etagMapper {
+		completedBytes += s.partSection(partNumber).Size()
+	}
+	s.progress.begin(s.fileSize, s.partCount, len(s.etagMapper), completedBytes)
+	defer s.abortIfFailed(&err)
+	err = s.PutObjectContext(ctx)
 	if err != nil {
 		return err
 	}
-	err = s.CompleteUploadObject()
+	err = s.CompleteUploadObjectContext(ctx)
 	if err != nil {
 		return err
 	}
-	defer s.file.Close()
+	if s.checkpointPath != "" {
+		return os.Remove(s.checkpointPath)
+	}
 	return nil
 }
 
+// abortIfFailed aborts the multipart upload when *err is not nil.
+// It is kept when abortOnFailure is disabled or checkpoint is saved to resume it.
+func (s *S3Upload) abortIfFailed(err *error) {
+	if *err == nil || !s.abortOnFailure || s.checkpointPath != "" {
+		return
+	}
+	// ctx may be already canceled, so abort is sent without it.
+	if abortErr := s.AbortMultipartUploadContext(context.Background()); abortErr != nil {
+		*err = xerrors.Errorf("failed to abort multipart upload %s (%v): %w", s.uploadID, abortErr, *err)
+	}
+}
+
+func (s *S3Upload) startUpload(ctx context.Context) error {
+	if s.resume && s.checkpointPath != "" {
+		checkpoint, err := LoadCheckpoint(s.checkpointPath)
+		if err == nil {
+			return s.resumeUpload(ctx, checkpoint)
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := s.InitialMultipartUploadContext(ctx); err != nil {
+		return err
+	}
+	return s.saveCheckpoint()
+}
+
 // InitialMultipartUpload is first request to do maltipart upload
 func (s *S3Upload) InitialMultipartUpload() error {
-	client := &http.Client{}
-	req, err := s.newInitialRequest()
-	res, err := client.Do(req)
+	return s.InitialMultipartUploadContext(context.Background())
+}
+
+// InitialMultipartUploadContext is InitialMultipartUpload with ctx
+func (s *S3Upload) InitialMultipartUploadContext(ctx context.Context) error {
+	res, err := s.do(ctx, s.newInitialRequest)
 	if err != nil {
 		return err
 	}
-	byteBody, err := ioutil.ReadAll(res.Body)
 	defer res.Body.Close()
-	s.xmlMapping(byteBody)
-	return nil
+	byteBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	return s.xmlMapping(byteBody)
 }
 
 func (s *S3Upload) newInitialRequest() (*http.Request, error) {
-	url := fmt.Sprintf("https://%s/%s?uploads", s.host, s.objectName)
+	url := s.objectURL("uploads")
 	req, err := http.NewRequest("POST", url, nil)
 	req.Header.Add("x-amz-date", time.Default.Now())
-	req.Header.Add("Host", s.host)
-	req.Header.Add("x-amz-content-sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", emptySHA256)
+	if s.checksum.flexible() {
+		req.Header.Add("x-amz-checksum-algorithm", string(s.checksum))
+	}
+	if s.copySource != nil {
+		if s.copySource.ContentType != "" {
+			req.Header.Add("Content-Type", s.copySource.ContentType)
+		}
+		for name, value := range s.copySource.Metadata {
+			req.Header.Add("x-amz-meta-"+name, value)
+		}
+	}
 	headerMap := s.convertToMap(req.Header)
 	authorization := s.signature.Authorization("POST", url, "", headerMap)
 	req.Header.Add("Authorization", authorization)
@@ -117,10 +319,42 @@ type initialRespXML struct {
 	UploadID string `xml:"UploadId"`
 }
 
-func (s *S3Upload) xmlMapping(respBody []byte) {
+func (s *S3Upload) xmlMapping(respBody []byte) error {
 	xmlMapper := initialRespXML{}
-	xml.Unmarshal(respBody, &xmlMapper)
+	if err := xml.Unmarshal(respBody, &xmlMapper); err != nil {
+		return xerrors.Errorf("invalid initiate multipart upload response: %w", err)
+	}
+	if xmlMapper.UploadID == "" {
+		return xerrors.New("initiate multipart upload response has no UploadId")
+	}
 	s.uploadID = xmlMapper.UploadID
+	return nil
+}
+
+// followRegion switches the endpoint and the signature to region and reports whether it switched.
+// S3 redirects only the first request to the bucket, so they are never switched while parts are uploaded.
+func (s *S3Upload) followRegion(region string) bool {
+	e, sig, ok := request.FollowRegion(s.endpoint, s.signature, region)
+	s.endpoint, s.signature = e, sig
+	return ok
+}
+
+func (s *S3Upload) host() string {
+	return s.endpoint.BucketHost(s.bucketName)
+}
+
+func (s *S3Upload) objectURL(query string) string {
+	return s.endpoint.URL(s.bucketName, s.objectName, query)
+}
+
+// uploadURL returns the URL of the object with query followed by uploadId of the multipart upload.
+// uploadId is URI-encoded, since an upload ID may have characters like '+', '/' and '='.
+func (s *S3Upload) uploadURL(query string) string {
+	uploadID := "uploadId=" + signature.URIEncode(s.uploadID, true)
+	if query == "" {
+		return s.objectURL(uploadID)
+	}
+	return s.objectURL(query + "&" + uploadID)
 }
 
 func (s *S3Upload) convertToMap(header http.Header) map[string]string {
@@ -133,83 +367,283 @@ func (s *S3Upload) convertToMap(header http.Header) map[string]string {
 
 // PutObject uploads file divided some chunk
 func (s *S3Upload) PutObject() error {
+	return s.PutObjectContext(context.Background())
+}
+
+// PutObjectContext uploads file divided some chunk with ctx.
+// At most concurrency parts are uploaded at the same time. Parts which already have ETag are skipped.
+// When a part fails or ctx is canceled, the remaining parts are not started.
+func (s *S3Upload) PutObjectContext(ctx context.Context) error {
+	pending := make([]int, 0, s.partCount)
+	for n := 1; n <= s.partCount; n++ {
+		if _, ok := s.etagMapper[n]; !ok {
+			pending = append(pending, n)
+		}
+	}
+	return s.uploadParts(ctx, func() (filePart, bool, error) {
+		if len(pending) == 0 {
+			return filePart{}, false, nil
+		}
+		n := pending[0]
+		pending = pending[1:]
+		return filePart{number: n, section: s.partSection(n)}, true, nil
+	})
+}
+
+// filePart is a part of multipart upload and the body of it.
+type filePart struct {
+	number  int
+	section *io.SectionReader
+}
+
+// uploadParts uploads parts given by nextPart with concurrency workers until nextPart returns false.
+// When a part fails or ctx is canceled, nextPart is not called any more.
+func (s *S3Upload) uploadParts(ctx context.Context, nextPart func() (filePart, bool, error)) error {
 	var wg sync.WaitGroup
+	queue := make(chan filePart)
 	errChan := make(chan error)
+	failed := make(chan struct{})
+	done := make(chan struct{})
 
-	var errors error
+	var firstErr error
 	go func() {
 		for err := range errChan {
-			if err != nil {
-				errors = multierror.Append(errors, err)
+			if err != nil && firstErr == nil {
+				firstErr = err
+				close(failed)
 			}
 		}
+		close(done)
 	}()
 
-	for n := range s.fileSlice {
+	for i := 0; i < s.concurrency; i++ {
 		wg.Add(1)
-		go func(n int) {
-			s.PutMultiPartObject(n+1, errChan)
+		go func() {
 			defer wg.Done()
-		}(n)
+			for part := range queue {
+				s.putPart(ctx, part, errChan)
+			}
+		}()
 	}
 
+	var nextErr error
+feed:
+	for {
+		part, ok, err := nextPart()
+		if err != nil {
+			nextErr = err
+			break
+		}
+		if !ok {
+			break
+		}
+		select {
+		case queue <- part:
+		case <-failed:
+			break feed
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(queue)
+
 	wg.Wait()
 	close(errChan)
-	return errors
+	<-done
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+	return nextErr
+}
+
+// PutSingleObject is request to upload the whole file without multipart upload
+func (s *S3Upload) PutSingleObject() error {
+	return s.PutSingleObjectContext(context.Background())
+}
+
+// PutSingleObjectContext is PutSingleObject with ctx
+func (s *S3Upload) PutSingleObjectContext(ctx context.Context) error {
+	return s.putSingle(ctx, io.NewSectionReader(s.file, 0, s.fileSize))
+}
+
+func (s *S3Upload) putSingle(ctx context.Context, section *io.SectionReader) error {
+	if err := s.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.limiter.release()
+	res, err := s.do(ctx, func() (*http.Request, error) {
+		return s.newSingleRequest(section)
+	})
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	s.progress.completePart()
+	return nil
 }
 
 // PutMultiPartObject is request to upload object
 func (s *S3Upload) PutMultiPartObject(partNumber int, errChan chan<- error) {
-	client := &http.Client{}
-	req, err := s.newUploaderRequest(partNumber)
-	res, err := client.Do(req)
+	s.PutMultiPartObjectContext(context.Background(), partNumber, errChan)
+}
+
+// PutMultiPartObjectContext is PutMultiPartObject with ctx
+func (s *S3Upload) PutMultiPartObjectContext(ctx context.Context, partNumber int, errChan chan<- error) {
+	s.putPart(ctx, filePart{number: partNumber, section: s.partSection(partNumber)}, errChan)
+}
+
+func (s *S3Upload) putPart(ctx context.Context, part filePart, errChan chan<- error) {
+	partNumber := part.number
+	if err := s.limiter.acquire(ctx); err != nil {
+		errChan <- err
+		return
+	}
+	defer s.limiter.release()
+	var etag string
+	var err error
+	if s.copySource != nil {
+		etag, err = s.copyPart(ctx, partNumber)
+	} else {
+		etag, err = s.sendPart(ctx, part)
+	}
 	if err != nil {
-		errChan <- xerrors.Errorf("error occurs when partNumber: %d, req: %v caused by : %w", partNumber, req, err)
+		s.progress.resetPart(partNumber)
+		errChan <- xerrors.Errorf("error occurs when partNumber: %d caused by : %w", partNumber, err)
+		return
+	}
+	if err := s.mutexMapInsert(partNumber, etag); err != nil {
+		errChan <- xerrors.Errorf("failed to save checkpoint: %w", err)
+		return
+	}
+	s.progress.completePart()
+}
+
+// sendPart uploads the body of part and returns ETag of it.
+func (s *S3Upload) sendPart(ctx context.Context, part filePart) (string, error) {
+	res, err := s.do(ctx, func() (*http.Request, error) {
+		return s.newPartRequest(part.number, part.section)
+	})
+	if err != nil {
+		return "", err
 	}
 	defer res.Body.Close()
 	etag := res.Header.Get("ETag")
-	s.mutexMapInsert(partNumber, etag)
+	if etag == "" {
+		return "", xerrors.New("no ETag in response")
+	}
+	return etag, nil
 }
 
-func (s *S3Upload) mutexMapInsert(partNumber int, etag string) {
+func (s *S3Upload) mutexMapInsert(partNumber int, etag string) error {
 	s.mutex.Lock()
-	s.etagMapper[partNumber] = etag
 	defer s.mutex.Unlock()
+	s.etagMapper[partNumber] = etag
+	return s.saveCheckpoint()
 }
 
+// devideFile calculates how many parts the file is divided into.
+// Each part is read from the file only when its request is built.
 func (s *S3Upload) devideFile() error {
-	byteSlice := make([][]byte, 0, 10)
-	for {
-		bytes := make([]byte, 1024*1024*5)
-		size, err := s.file.Read(bytes)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return err
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	s.fileSize = info.Size()
+	s.modTime = info.ModTime()
+	s.partSize, err = choosePartSize(s.fileSize, s.partSize)
+	if err != nil {
+		return err
+	}
+	s.partCount = int((s.fileSize + s.partSize - 1) / s.partSize)
+	return nil
+}
 
+// choosePartSize validates partSize for a file of fileSize.
+// When partSize is 0, it returns the smallest part size, rounded up to MiB,
+// that divides the file into at most maxParts parts.
+func choosePartSize(fileSize, partSize int64) (int64, error) {
+	if fileSize > maxObjectSize {
+		return 0, xerrors.Errorf("file size %d exceeds the maximum object size %d", fileSize, int64(maxObjectSize))
+	}
+	if partSize == 0 {
+		partSize = (fileSize + maxParts - 1) / maxParts
+		partSize = (partSize + 1024*1024 - 1) / (1024 * 1024) * (1024 * 1024)
+		if partSize < minPartSize {
+			partSize = minPartSize
 		}
-		if size != 1024*1024*5 {
-			byteSlice = append(byteSlice, bytes[:size])
-			continue
-		}
-		byteSlice = append(byteSlice, bytes)
+		return partSize, nil
 	}
-	s.fileSlice = byteSlice
-	return nil
+	if partSize < minPartSize || partSize > maxPartSize {
+		return 0, xerrors.Errorf("part size %d must be between %d and %d", partSize, minPartSize, int64(maxPartSize))
+	}
+	if parts := (fileSize + partSize - 1) / partSize; parts > maxParts {
+		return 0, xerrors.Errorf("part size %d divides the file into %d parts, more than %d", partSize, parts, maxParts)
+	}
+	return partSize, nil
+}
+
+func (s *S3Upload) partSection(partNumber int) *io.SectionReader {
+	offset, size := s.partRange(partNumber)
+	return io.NewSectionReader(s.file, offset, size)
+}
+
+// partRange returns the offset and the size of partNumber in the file.
+func (s *S3Upload) partRange(partNumber int) (int64, int64) {
+	offset := int64(partNumber-1) * s.partSize
+	size := s.fileSize - offset
+	if size > s.partSize {
+		size = s.partSize
+	}
+	return offset, size
 }
 
 func (s *S3Upload) newUploaderRequest(partNumber int) (*http.Request, error) {
-	url := fmt.Sprintf("https://%s/%s?partNumber=%d&uploadId=%s", s.host, s.objectName, partNumber, s.uploadID)
-	byteBody := s.fileSlice[partNumber-1]
-	buffer := bytes.NewBuffer(byteBody)
-	req, err := http.NewRequest("PUT", url, buffer)
+	return s.newPartRequest(partNumber, s.partSection(partNumber))
+}
+
+func (s *S3Upload) newPartRequest(partNumber int, section *io.SectionReader) (*http.Request, error) {
+	url := s.uploadURL(fmt.Sprintf("partNumber=%d", partNumber))
+	return s.newPutRequest(url, partNumber, section)
+}
+
+func (s *S3Upload) newSingleRequest(section *io.SectionReader) (*http.Request, error) {
+	url := s.objectURL("")
+	return s.newPutRequest(url, 1, section)
+}
+
+// newPutRequest reads section from its beginning, so that the same section can be sent again by retry.
+func (s *S3Upload) newPutRequest(url string, partNumber int, section *io.SectionReader) (*http.Request, error) {
+	if s.streaming {
+		return s.newStreamingPutRequest(url, partNumber, section)
+	}
+	byteBody, err := ioutil.ReadAll(io.NewSectionReader(section, 0, section.Size()))
+	if err != nil {
+		return nil, err
+	}
+	body := s.progress.reader(partNumber, bytes.NewReader(byteBody))
+	req, err := http.NewRequest("PUT", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(byteBody))
 	req.Header.Add("x-amz-date", time.Default.Now())
-	req.Header.Add("Host", s.host)
-	req.Header.Add("x-amz-content-sha256", hashSHA256(string(byteBody)))
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", s.payloadHash(byteBody))
 	req.Header.Add("Content-Length", strconv.Itoa(len(byteBody)))
+	if s.checksum != "" {
+		name, value, err := s.checksum.checksumHeader(byteBody)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add(name, value)
+		s.setPartChecksum(partNumber, value)
+	}
 	headerMap := s.convertToMap(req.Header)
-	authorization := s.signature.Authorization("PUT", url, string(byteBody), headerMap)
+	// The payload is hashed by x-amz-content-sha256 header, so it is not given to the signature.
+	authorization := s.signature.Authorization("PUT", url, "", headerMap)
 	req.Header.Add("Authorization", authorization)
 	return req, err
 }
@@ -226,16 +660,26 @@ func hashSHA256(payload string) string {
 
 // CompleteUploadObject is request to finish upload part
 func (s *S3Upload) CompleteUploadObject() error {
-	client := &http.Client{}
-	req, err := s.newCompleteRequest()
-	res, err := client.Do(req)
+	return s.CompleteUploadObjectContext(context.Background())
+}
+
+// CompleteUploadObjectContext is CompleteUploadObject with ctx
+func (s *S3Upload) CompleteUploadObjectContext(ctx context.Context) error {
+	res, err := s.do(ctx, s.newCompleteRequest)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
-	// byteBody, err := ioutil.ReadAll(res.Body)
-	// fmt.Println(res.StatusCode, string(byteBody))
-	return err
+	byteBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	// S3 may report an error with 200 OK after the response has started.
+	if s3Err := request.ParseS3Error(byteBody); s3Err != nil {
+		s3Err.StatusCode = res.StatusCode
+		return s3Err
+	}
+	return nil
 }
 
 // CompleteMultipartUpload struct is to be base XML For Reuqest
@@ -246,9 +690,11 @@ type CompleteMultipartUpload struct {
 
 // Part is included in the CompleteMultipartUpload XML.
 type Part struct {
-	XMLName    xml.Name `xml:"Part"`
-	PartNumber int      `xml:"PartNumber"`
-	ETag       string   `xml:"ETag"`
+	XMLName        xml.Name `xml:"Part"`
+	PartNumber     int      `xml:"PartNumber"`
+	ETag           string   `xml:"ETag"`
+	ChecksumCRC32  string   `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C string   `xml:"ChecksumCRC32C,omitempty"`
 }
 
 // Parts implements Sort Interface
@@ -262,7 +708,7 @@ func (s *S3Upload) generateXML() (string, error) {
 	parts := make([]Part, 0, 10)
 	for key, value := range s.etagMapper {
 		part := Part{PartNumber: key, ETag: value}
-		parts = append(parts, part)
+		parts = append(parts, s.partChecksum(part))
 	}
 	sort.Sort(Parts(parts))
 	comleteMultipartUpload := CompleteMultipartUpload{Part: parts}
@@ -274,7 +720,7 @@ func (s *S3Upload) generateXML() (string, error) {
 }
 
 func (s *S3Upload) newCompleteRequest() (*http.Request, error) {
-	url := fmt.Sprintf("https://%s/%s?uploadId=%s", s.host, s.objectName, s.uploadID)
+	url := s.uploadURL("")
 	xmlString, err := s.generateXML()
 	if err != nil {
 		return nil, err
@@ -282,7 +728,7 @@ func (s *S3Upload) newCompleteRequest() (*http.Request, error) {
 	reader := strings.NewReader(xmlString)
 	req, err := http.NewRequest("POST", url, reader)
 	req.Header.Add("x-amz-date", time.Default.Now())
-	req.Header.Add("Host", s.host)
+	req.Header.Add("Host", s.host())
 	req.Header.Add("x-amz-content-sha256", hashSHA256(xmlString))
 	req.Header.Add("Content-Length", strconv.Itoa(len(xmlString)))
 	headerMap := s.convertToMap(req.Header)
@@ -290,3 +736,89 @@ func (s *S3Upload) newCompleteRequest() (*http.Request, error) {
 	req.Header.Add("Authorization", authorization)
 	return req, err
 }
+
+// AbortMultipartUpload is request to discard the multipart upload and its uploaded parts
+func (s *S3Upload) AbortMultipartUpload() error {
+	return s.AbortMultipartUploadContext(context.Background())
+}
+
+// AbortMultipartUploadContext is AbortMultipartUpload with ctx
+func (s *S3Upload) AbortMultipartUploadContext(ctx context.Context) error {
+	res, err := s.do(ctx, s.newAbortRequest)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (s *S3Upload) newAbortRequest() (*http.Request, error) {
+	url := s.uploadURL("")
+	req, err := http.NewRequest("DELETE", url, nil)
+	req.Header.Add("x-amz-date", time.Default.Now())
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", emptySHA256)
+	headerMap := s.convertToMap(req.Header)
+	authorization := s.signature.Authorization("DELETE", url, "", headerMap)
+	req.Header.Add("Authorization", authorization)
+	return req, err
+}
+
+// ListPartsResult is response XML of ListParts request
+type ListPartsResult struct {
+	IsTruncated          bool
+	NextPartNumberMarker int
+	Part                 []UploadedPart
+}
+
+// UploadedPart is a part which has been uploaded to S3.
+type UploadedPart struct {
+	PartNumber     int
+	ETag           string
+	Size           int64
+	ChecksumCRC32  string
+	ChecksumCRC32C string
+}
+
+// ListParts is request to get parts uploaded for the multipart upload.
+// It follows pagination until all parts are listed.
+func (s *S3Upload) ListParts() ([]UploadedPart, error) {
+	return s.ListPartsContext(context.Background())
+}
+
+// ListPartsContext is ListParts with ctx
+func (s *S3Upload) ListPartsContext(ctx context.Context) ([]UploadedPart, error) {
+	parts := make([]UploadedPart, 0, 10)
+	marker := 0
+	for {
+		res, err := s.do(ctx, func() (*http.Request, error) {
+			return s.newListPartsRequest(marker)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result := ListPartsResult{}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, xerrors.Errorf("invalid list parts response: %w", err)
+		}
+		parts = append(parts, result.Part...)
+		if !result.IsTruncated {
+			return parts, nil
+		}
+		marker = result.NextPartNumberMarker
+	}
+}
+
+func (s *S3Upload) newListPartsRequest(marker int) (*http.Request, error) {
+	url := s.uploadURL(fmt.Sprintf("part-number-marker=%d", marker))
+	req, err := http.NewRequest("GET", url, nil)
+	req.Header.Add("x-amz-date", time.Default.Now())
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", emptySHA256)
+	headerMap := s.convertToMap(req.Header)
+	authorization := s.signature.Authorization("GET", url, "", headerMap)
+	req.Header.Add("Authorization", authorization)
+	return req, err
+}
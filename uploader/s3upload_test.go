@@ -1,25 +1,37 @@
 package uploader
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/hikaru7719/s3go/endpoint"
 	"github.com/hikaru7719/s3go/signature"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
 )
 
 func TestInitialMultipartUpload(t *testing.T) {
 	sig := signature.New()
-	upload, _ := New(os.Getenv("AWS_S3_BUCKET_NAME"), "../testdata/earth.jpg", sig)
+	upload, err := New(os.Getenv("AWS_S3_BUCKET_NAME"), "../testdata/earth.jpg", sig)
+	// This test sends a request to a real bucket with a file which is not in the repository.
+	if os.Getenv("AWS_S3_BUCKET_NAME") == "" || err != nil {
+		t.Skip("AWS_S3_BUCKET_NAME or ../testdata/earth.jpg is not available")
+	}
 	upload.InitialMultipartUpload()
 }
 
 func TestNewInitialRequest(t *testing.T) {
 
 	uploader := &S3Upload{
-		host:       "testhost",
+		endpoint:   &endpoint.Endpoint{Scheme: "https", Host: "testhost", PathStyle: true},
 		bucketName: "testbucket",
 		objectName: "testObject",
 		signature:  &mockAuth{},
@@ -47,23 +59,34 @@ func (m *mockAuth) Authorization(method, URL, payload string, header map[string]
 
 func TestNewUploadRequest(t *testing.T) {
 	upload := &S3Upload{
-		host:       "testhost",
+		endpoint:   &endpoint.Endpoint{Scheme: "https", Host: "testhost", PathStyle: true},
 		bucketName: "testbucket",
 		objectName: "testObject",
 		signature:  &mockAuth{},
 	}
-	bytes := []byte("hoge")
-	fileSlice := [][]byte{bytes}
-	upload.fileSlice = fileSlice
+	upload.file = tempFile(t, []byte("hoge"))
+	defer os.Remove(upload.file.Name())
+	upload.devideFile()
 	req, _ := upload.newUploaderRequest(1)
 	assert.Equal(t, "4", req.Header.Get("content-length"))
 }
 
+func tempFile(t *testing.T, content []byte) *os.File {
+	file, err := ioutil.TempFile("", "s3go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
 func TestNewCompleteRequest(t *testing.T) {
 	etagMap := make(map[int]string)
 	etagMap[1] = "testetag1"
 	upload := &S3Upload{
-		host:       "testhost",
+		endpoint:   &endpoint.Endpoint{Scheme: "https", Host: "testhost", PathStyle: true},
 		bucketName: "testbucket",
 		objectName: "testObject",
 		signature:  &mockAuth{},
@@ -109,10 +132,13 @@ func TestGenerateXML(t *testing.T) {
 
 func TestDevideFile(t *testing.T) {
 	upload := &S3Upload{}
-	file, _ := os.Open("../testdata/earth.jpg")
-	upload.file = file
+	upload.file = tempFile(t, nil)
+	defer os.Remove(upload.file.Name())
+	upload.file.Truncate(minPartSize*3 + 1)
 	upload.devideFile()
-	assert.Equal(t, 4, len(upload.fileSlice))
+	assert.Equal(t, 4, upload.partCount)
+	assert.Equal(t, int64(minPartSize), upload.partSection(3).Size())
+	assert.Equal(t, int64(1), upload.partSection(4).Size())
 }
 
 func TestMutexMapInsert(t *testing.T) {
@@ -138,3 +164,283 @@ func TestMutexMapInsert(t *testing.T) {
 	assert.Equal(t, "hoge", uploader.etagMapper[1])
 	assert.Equal(t, "fuga", uploader.etagMapper[2])
 }
+
+func TestPutObjectConcurrency(t *testing.T) {
+	var mutex sync.Mutex
+	var inFlight, maxInFlight int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+		w.Header().Set("ETag", r.URL.Query().Get("partNumber"))
+	}))
+	defer server.Close()
+
+	upload := &S3Upload{
+		endpoint:    testEndpoint(server),
+		objectName:  "testObject",
+		signature:   &mockAuth{},
+		etagMapper:  make(map[int]string),
+		mutex:       new(sync.Mutex),
+		client:      server.Client(),
+		concurrency: 2,
+	}
+	upload.file = tempFile(t, nil)
+	defer os.Remove(upload.file.Name())
+	upload.file.Truncate(minPartSize*5 + 1)
+	upload.devideFile()
+
+	err := upload.PutObject()
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(upload.etagMapper))
+	assert.Equal(t, "6", upload.etagMapper[6])
+	assert.True(t, maxInFlight <= 2)
+}
+
+func testEndpoint(server *httptest.Server) *endpoint.Endpoint {
+	e, _ := endpoint.Parse(server.URL, true)
+	return e
+}
+
+func newTestUpload(t *testing.T, server *httptest.Server, size int64) *S3Upload {
+	upload := &S3Upload{
+		endpoint:    testEndpoint(server),
+		bucketName:  "testbucket",
+		objectName:  "testObject",
+		signature:   &mockAuth{},
+		etagMapper:  make(map[int]string),
+		mutex:       new(sync.Mutex),
+		client:      server.Client(),
+		concurrency: 2,
+	}
+	upload.file = tempFile(t, nil)
+	upload.file.Truncate(size)
+	return upload
+}
+
+func TestRunAbortOnFailure(t *testing.T) {
+	cases := map[string]struct {
+		abortOnFailure bool
+		expectAborted  bool
+	}{
+		"abort":    {abortOnFailure: true, expectAborted: true},
+		"no abort": {abortOnFailure: false, expectAborted: false},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var aborted bool
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+				case "PUT":
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`<Error><Code>AccessDenied</Code></Error>`))
+				case "DELETE":
+					aborted = r.URL.Query().Get("uploadId") == "testUploadID"
+					w.WriteHeader(http.StatusNoContent)
+				}
+			}))
+			defer server.Close()
+
+			upload := newTestUpload(t, server, minPartSize+1)
+			defer os.Remove(upload.file.Name())
+			upload.abortOnFailure = tc.abortOnFailure
+
+			err := upload.Run()
+			var s3Err *S3Error
+			assert.True(t, xerrors.As(err, &s3Err))
+			assert.Equal(t, ErrCodeAccessDenied, s3Err.Code)
+			assert.Equal(t, tc.expectAborted, aborted)
+		})
+	}
+}
+
+func TestRunSingleObject(t *testing.T) {
+	cases := map[string]struct {
+		testSize      int64
+		testThreshold int64
+	}{
+		"small file":             {testSize: 1024, testThreshold: defaultThreshold},
+		"empty file":             {testSize: 0, testThreshold: defaultThreshold},
+		"empty file threshold 0": {testSize: 0, testThreshold: 0},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			requests := make([]string, 0, 1)
+			var length int64
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.RequestURI())
+				length = r.ContentLength
+			}))
+			defer server.Close()
+
+			upload := newTestUpload(t, server, tc.testSize)
+			defer os.Remove(upload.file.Name())
+			upload.multipartThreshold = tc.testThreshold
+
+			err := upload.Run()
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"PUT /testbucket/testObject"}, requests)
+			assert.Equal(t, tc.testSize, length)
+		})
+	}
+}
+
+func TestChoosePartSize(t *testing.T) {
+	cases := map[string]struct {
+		testFileSize   int64
+		testPartSize   int64
+		expectPartSize int64
+		expectError    bool
+	}{
+		"auto small file":           {testFileSize: 1024, expectPartSize: minPartSize},
+		"auto 100GiB file":          {testFileSize: 100 << 30, expectPartSize: 11 << 20},
+		"auto max object":           {testFileSize: maxObjectSize, expectPartSize: 525 << 20},
+		"too large object":          {testFileSize: maxObjectSize + 1, expectError: true},
+		"explicit part size":        {testFileSize: 1 << 30, testPartSize: 64 << 20, expectPartSize: 64 << 20},
+		"part size too small":       {testFileSize: 1 << 30, testPartSize: 1 << 20, expectError: true},
+		"part size too large":       {testFileSize: 1 << 30, testPartSize: maxPartSize + 1, expectError: true},
+		"too many parts":            {testFileSize: 100 << 30, testPartSize: minPartSize, expectError: true},
+		"exactly maximum of parts":  {testFileSize: minPartSize * maxParts, testPartSize: minPartSize, expectPartSize: minPartSize},
+		"auto just over many parts": {testFileSize: minPartSize*maxParts + 1, expectPartSize: 6 << 20},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual, err := choosePartSize(tc.testFileSize, tc.testPartSize)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectPartSize, actual)
+		})
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mutex sync.Mutex
+	var puts int
+	var aborted bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+		case "PUT":
+			mutex.Lock()
+			puts++
+			mutex.Unlock()
+			ioutil.ReadAll(r.Body)
+			cancel()
+			<-r.Context().Done()
+		case "DELETE":
+			mutex.Lock()
+			aborted = true
+			mutex.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	upload := newTestUpload(t, server, minPartSize*10)
+	defer os.Remove(upload.file.Name())
+	upload.abortOnFailure = true
+	upload.concurrency = 1
+
+	err := upload.RunContext(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, puts)
+	assert.True(t, aborted)
+}
+
+func TestFollowRegion(t *testing.T) {
+	upload := &S3Upload{
+		bucketName: "testbucket",
+		endpoint:   endpoint.Default,
+		signature:  signature.New(),
+	}
+	assert.True(t, upload.followRegion("eu-west-1"))
+	assert.Equal(t, "testbucket.s3.eu-west-1.amazonaws.com", upload.host())
+	assert.False(t, upload.followRegion("eu-west-1"))
+
+	custom := &S3Upload{
+		bucketName: "testbucket",
+		endpoint:   &endpoint.Endpoint{Scheme: "http", Host: "localhost", PathStyle: true},
+		signature:  signature.New(),
+	}
+	assert.False(t, custom.followRegion("eu-west-1"))
+
+	mock := &S3Upload{
+		bucketName: "testbucket",
+		endpoint:   endpoint.Default,
+		signature:  &mockAuth{},
+	}
+	assert.False(t, mock.followRegion("eu-west-1"))
+}
+
+func TestUploadURL(t *testing.T) {
+	cases := map[string]struct {
+		testUploadID string
+		testQuery    string
+		expectURL    string
+	}{
+		"part": {
+			testUploadID: "testUploadID",
+			testQuery:    "partNumber=1",
+			expectURL:    "https://testhost/testbucket/testObject?partNumber=1&uploadId=testUploadID",
+		},
+		"reserved characters": {
+			testUploadID: "a+b/c=",
+			expectURL:    "https://testhost/testbucket/testObject?uploadId=a%2Bb%2Fc%3D",
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			upload := &S3Upload{
+				endpoint:   &endpoint.Endpoint{Scheme: "https", Host: "testhost", PathStyle: true},
+				bucketName: "testbucket",
+				objectName: "testObject",
+				uploadID:   tc.testUploadID,
+			}
+			actual := upload.uploadURL(tc.testQuery)
+			assert.Equal(t, tc.expectURL, actual)
+			u, _ := url.Parse(actual)
+			assert.Equal(t, tc.testUploadID, u.Query().Get("uploadId"))
+		})
+	}
+}
+
+func TestRunWithKey(t *testing.T) {
+	var requestURI, path string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURI = r.RequestURI
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	upload := newTestUpload(t, server, 10)
+	defer os.Remove(upload.file.Name())
+	upload.multipartThreshold = defaultThreshold
+	WithKey("backup/2019 08/写真 (1).jpg")(upload)
+
+	err := upload.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, "/testbucket/backup/2019%2008/%E5%86%99%E7%9C%9F%20%281%29.jpg", requestURI)
+	assert.Equal(t, "/testbucket/backup/2019 08/写真 (1).jpg", path)
+}
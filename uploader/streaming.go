@@ -0,0 +1,55 @@
+package uploader
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/hikaru7719/s3go/time"
+	"golang.org/x/xerrors"
+)
+
+// WithStreamingSignature sends bodies with aws-chunked encoding signed chunk by chunk,
+// so that each part is streamed from the file instead of being read into memory and hashed before it is sent.
+// Signature must implement NewChunkedReader like *signature.Signature.
+func WithStreamingSignature() Option {
+	return func(s *S3Upload) {
+		s.streaming = true
+	}
+}
+
+// chunkedSignature is implemented by Signature which can sign aws-chunked payload.
+type chunkedSignature interface {
+	NewChunkedReader(r io.Reader, chunkSize int, requestTime, authorization string) io.Reader
+}
+
+// newStreamingPutRequest returns PUT request whose body is section encoded with aws-chunked.
+// section is read from its beginning when the body is read, so that the same section can be sent again by retry.
+func (s *S3Upload) newStreamingPutRequest(url string, partNumber int, section *io.SectionReader) (*http.Request, error) {
+	sig, ok := s.signature.(chunkedSignature)
+	if !ok {
+		return nil, xerrors.New("signature doesn't support streaming payload")
+	}
+	req, err := http.NewRequest("PUT", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Default.Now()
+	length := signature.ChunkedLength(section.Size(), signature.DefaultChunkSize)
+	req.Header.Add("x-amz-date", now)
+	req.Header.Add("Host", s.host())
+	req.Header.Add("x-amz-content-sha256", signature.StreamingPayload)
+	req.Header.Add("Content-Encoding", "aws-chunked")
+	req.Header.Add("x-amz-decoded-content-length", strconv.FormatInt(section.Size(), 10))
+	req.Header.Add("Content-Length", strconv.FormatInt(length, 10))
+	headerMap := s.convertToMap(req.Header)
+	authorization := s.signature.Authorization("PUT", url, "", headerMap)
+	req.Header.Add("Authorization", authorization)
+
+	body := s.progress.reader(partNumber, io.NewSectionReader(section, 0, section.Size()))
+	req.Body = ioutil.NopCloser(sig.NewChunkedReader(body, signature.DefaultChunkSize, now, authorization))
+	req.ContentLength = length
+	return req, nil
+}
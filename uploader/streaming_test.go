@@ -0,0 +1,127 @@
+package uploader
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hikaru7719/s3go/config"
+	"github.com/hikaru7719/s3go/endpoint"
+	"github.com/hikaru7719/s3go/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeChunked decodes aws-chunked body and returns the data and the number of chunks including the last empty one.
+func decodeChunked(t *testing.T, body io.Reader) ([]byte, int) {
+	reader := bufio.NewReader(body)
+	var data bytes.Buffer
+	chunks := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		header := strings.SplitN(strings.TrimSuffix(line, "\r\n"), ";chunk-signature=", 2)
+		if len(header) != 2 || len(header[1]) != 64 {
+			t.Fatalf("invalid chunk header: %q", line)
+		}
+		size, err := strconv.ParseInt(header[0], 16, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks++
+		if _, err := io.CopyN(&data, reader, size+2); err != nil {
+			t.Fatal(err)
+		}
+		data.Truncate(data.Len() - 2)
+		if size == 0 {
+			return data.Bytes(), chunks
+		}
+	}
+}
+
+func TestRunStreamingSignature(t *testing.T) {
+	cases := map[string]struct {
+		testSize     int64
+		expectChunks map[string]int
+	}{
+		"single object": {
+			testSize:     1024,
+			expectChunks: map[string]int{"": 2},
+		},
+		"multipart": {
+			testSize:     minPartSize + 1,
+			expectChunks: map[string]int{"1": minPartSize/signature.DefaultChunkSize + 1, "2": 2},
+		},
+	}
+
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			var mutex sync.Mutex
+			chunks := make(map[string]int)
+			var received int64
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "POST":
+					w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>testUploadID</UploadId></InitiateMultipartUploadResult>`))
+				case "PUT":
+					assert.Equal(t, signature.StreamingPayload, r.Header.Get("x-amz-content-sha256"))
+					assert.Equal(t, "aws-chunked", r.Header.Get("Content-Encoding"))
+					size, _ := strconv.ParseInt(r.Header.Get("x-amz-decoded-content-length"), 10, 64)
+					assert.Equal(t, signature.ChunkedLength(size, signature.DefaultChunkSize), r.ContentLength)
+					data, count := decodeChunked(t, r.Body)
+					assert.Equal(t, size, int64(len(data)))
+					mutex.Lock()
+					chunks[r.URL.Query().Get("partNumber")] = count
+					received += size
+					mutex.Unlock()
+					w.Header().Set("ETag", `"etag"`)
+				}
+			}))
+			defer server.Close()
+
+			upload := newTestUpload(t, server, tc.testSize)
+			defer os.Remove(upload.file.Name())
+			upload.multipartThreshold = minPartSize
+			upload.retry = DefaultRetryPolicy()
+			upload.signature = signature.NewFromConfig(&config.Config{AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "us-east-1"})
+			WithStreamingSignature()(upload)
+
+			err := upload.Run()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectChunks, chunks)
+			assert.Equal(t, tc.testSize, received)
+		})
+	}
+}
+
+func TestNewStreamingPutRequestUnsupportedSignature(t *testing.T) {
+	upload := &S3Upload{
+		endpoint:   &endpoint.Endpoint{Scheme: "https", Host: "testhost", PathStyle: true},
+		bucketName: "testbucket",
+		objectName: "testObject",
+		signature:  &mockAuth{},
+		streaming:  true,
+	}
+	upload.file = tempFile(t, []byte("hoge"))
+	defer os.Remove(upload.file.Name())
+	upload.devideFile()
+	_, err := upload.newUploaderRequest(1)
+	assert.Error(t, err)
+}
+
+func TestRunStreamingSignatureWithUnsignedPayload(t *testing.T) {
+	f := tempFile(t, []byte("hoge"))
+	defer os.Remove(f.Name())
+	upload, err := New("testbucket", f.Name(), &mockAuth{}, WithStreamingSignature(), WithUnsignedPayload(ChecksumCRC32))
+	assert.NoError(t, err)
+	assert.Error(t, upload.Run())
+}